@@ -4,22 +4,40 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
 	"reflect"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mitoteam/mttools"
+	"github.com/santhosh-tekuri/jsonschema/v6"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 const DEV_MODE_LABEL = "DEV"
 const MOTTO = "Making world better since 2005"
 
+// cookie secret used in DEV mode if no one was set in settings
+const devCookieSecret = "DEFAULT_DEV_SECRET"
+
+// Graceful shutdown stages reported to OnShutdownProgressF
+const (
+	ShutdownStageDraining          = "draining"           // waiting for web server to finish active requests
+	ShutdownStageWaitingGoroutines = "waiting-goroutines" // waiting for goroutines started with Go(), then PostRunF is called
+	ShutdownStageClosingDb         = "closing-db"         // closing database
+	ShutdownStageDone              = "done"               // shutdown complete
+)
+
 // Variables to be set by compiler
 var (
 	BuildVersion = DEV_MODE_LABEL
@@ -27,6 +45,11 @@ var (
 	BuildTime    = DEV_MODE_LABEL
 )
 
+type versionComponent struct {
+	name    string
+	version string
+}
+
 type AppBase struct {
 	ExecutableName  string //executable command name
 	AppName         string //Long name
@@ -39,45 +62,106 @@ type AppBase struct {
 	BuildWith       string    //Build information
 	StartTime       time.Time //Startup timestamp
 
+	versionComponents []versionComponent //additional components versions (see AddVersionComponent())
+
 	License string //License information to print with `license` command
 
 	Global map[string]interface{} //some global application state values
 
-	AppSettingsFilename string           // with .yml extension please
-	AppSettings         interface{}      //pointer to struct embedding AppSettingsBase
-	baseSettings        *AppSettingsBase //pointer to *AppSettingsBase, set in internalInit()
+	AppSettingsFilename  string                      // with .yml extension please
+	SettingsMaxFileSize  int64                       // settings file size limit in bytes (default 1 MB)
+	SettingsMaxDepth     int                         // settings file nesting depth limit (default 32)
+	SettingsFetchTimeout time.Duration               // timeout to fetch settings if AppSettingsFilename is http(s) URL (default 10s)
+	DotEnvFilename       string                      // .env file loaded to environment before settings (default ".env", empty to disable)
+	SettingsEnvPrefix    string                      // environment variables prefix to override settings (like "MYAPP_"), empty to disable
+	AppSettings          interface{}                 //pointer to struct embedding AppSettingsBase
+	baseSettings         *AppSettingsBase            //pointer to *AppSettingsBase, set in internalInit()
+	settingsWarnings     []string                    //non-fatal settings issues found by loadSettings()
+	settingsFlagBindings []*settingsFlagBinding      //persistent flags bound to settings fields (see BindFlag())
+	settingsOrigins      map[settingsFieldKey]string //where settings values were taken from (see SettingsOrigin())
+
+	demoMode bool //ephemeral instance started with RunDemo()
 
 	serviceAutostart bool
 
-	rootCmd *cobra.Command
+	rootCmd     *cobra.Command
+	currentCmd  *cobra.Command //subcommand being executed (set before PreCmdF is called)
+	currentArgs []string       //current subcommand arguments
 
 	//base app context to be used
 	BaseContext context.Context
 	//called when application is being shutdown (set by context.WithCancel)
 	appShutdownF context.CancelFunc
-	//timeout for webserver shutdown
+	//web requests base context (derived from BaseContext, cancelled earlier during shutdown)
+	requestsContext context.Context
+	cancelRequestsF context.CancelFunc
+	//timeout for whole graceful shutdown (see gracefulShutdown())
 	ShutdownTimeout time.Duration
+	//timeout for PreRunF, startup is aborted if it is exceeded (default 0 = no timeout)
+	PreRunTimeout time.Duration
+	//background goroutines started with Go() (waited for on shutdown)
+	backgroundWg sync.WaitGroup
+	//closed by Shutdown() to stop run command same way as SIGINT does
+	shutdownRequested chan struct{}
+	shutdownOnce      sync.Once
+	//run command is serving requests (Shutdown() leaves BaseContext cancelling to graceful shutdown)
+	serving atomic.Bool
+	//startup is finished and app is not shutting down (see readiness endpoint)
+	ready atomic.Bool
+
+	//flushes and stops OpenTelemetry tracing (nil if tracing is disabled)
+	tracingShutdownF func(ctx context.Context) error
+
+	//in-process event bus (see Subscribe() and Publish())
+	events eventBus
 
 	//web routers
 	ginEngine            *gin.Engine
-	WebRouterLogRequests bool                // true = extended web request logging (--log-request option of `run`)
-	BuildWebRouterF      func(r *gin.Engine) // function to build web router for `run` command
+	WebRouterLogRequests bool                   // true = extended web request logging (--log-request option of `run`)
+	BuildWebRouterF      func(r *gin.Engine)    // function to build web router for `run` command
+	ConfigureHttpServerF func(srv *http.Server) // called before `run` command starts listening to adjust server (TLSConfig, ConnState, ErrorLog, etc)
 	webHandler           http.Handler
+	webCache             *webResponseCache // responses cache (nil if no cached routes set in settings)
+	webInspector         *webInspector     // recent requests for inspector page (DEV mode only)
+	webMetrics           *webMetrics       // Prometheus metrics (nil if metrics are disabled)
 
 	//web api
-	WebApiPathPrefix  string // usually "/api". Leave empty to disable web API at all.
-	WebApiEnableGet   bool   // Serve both POST and GET methods. Default 'false' = POST-requests only.
-	webApiHandlerList map[string]ApiRequestHandler
+	WebApiPathPrefix     string                                         // usually "/api". Leave empty to disable web API at all.
+	WebApiEnableGet      bool                                           // Serve both POST and GET methods. Default 'false' = POST-requests only.
+	WebApiUseEnvelope    bool                                           // wrap API replies to {"ok": bool, "data": ..., "error": "..."} envelope (handler errors get non-200 status)
+	ApiAuthF             func(c *gin.Context) (identity any, err error) // authenticates API requests (401 on error). Identity is available with ApiRequest.Identity().
+	webApiHandlerList    map[string]ApiRequestHandler
+	webApiSchemaList     map[string]*jsonschema.Schema // request body JSON schemas (path => schema)
+	webApiMiddlewareList map[string][]gin.HandlerFunc  // per-handler middleware (path => middleware list)
+	webApiMethodList     map[string][]string           // accepted HTTP methods (path => methods), see ApiMethods()
+	webApiRateLimiter    *webRateLimiter               // per client IP rate limiter (nil if webapi_rate_limit is not set)
+
+	//custom error pages for non-API routes (status => handler)
+	webErrorPageHandlerList map[int]gin.HandlerFunc
+
+	//health endpoint details (name => value function)
+	healthDetailList map[string]func() any
+
+	//app-specific checks for `check-connectivity` command (see AddConnectivityCheck())
+	connectivityCheckList []connectivityCheck
+
+	//static assets filesystems (url prefix => assets)
+	webStaticAssetsList map[string]*webStaticAssets
 
 	//callbacks (aka event handlers)
 	PreCmdF  func(cmd *cobra.Command) error // called before any subcommand. Stops executions if error returned.
 	PostCmdF func(cmd *cobra.Command) error // called after any subcommand. Stops executions if error returned.
 
-	PreRunF    func() error // called before starting `run` command. Stops executions if error returned.
+	PreRunF    func() error // called before starting `run` command (flags are available with CurrentCmd()). Stops executions if error returned.
 	PostRunF   func() error // called after finishing `run` command. Stops executions if error returned.
 	InitF      func() error // Additional code for `init` subcommand. Stops executions if error returned.
 	PrintInfoF func()       // Prints additional information when `info` subcommand called.
 
+	OnShutdownProgressF func(stage string) // called at each graceful shutdown stage (see ShutdownStage* constants)
+
+	ErrorReporterF     func(err error, ctx map[string]any) // called for web request panics and fatal command errors (to forward them to Sentry etc)
+	ReportServerErrors bool                                // report web requests finished with 5xx status to ErrorReporterF too
+
 	BuildCustomCommandsF func(rootCmd *cobra.Command) // Set this to add any custom subcommands
 }
 
@@ -94,17 +178,32 @@ func NewAppBase(defaultSettings interface{}) *AppBase {
 
 	//web api routes list
 	app.webApiHandlerList = make(map[string]ApiRequestHandler)
+	app.webApiSchemaList = make(map[string]*jsonschema.Schema)
+	app.webApiMiddlewareList = make(map[string][]gin.HandlerFunc)
+	app.webApiMethodList = make(map[string][]string)
+
+	//custom error pages list
+	app.webErrorPageHandlerList = make(map[int]gin.HandlerFunc)
+
+	//health details list
+	app.healthDetailList = make(map[string]func() any)
+
+	//static assets list
+	app.webStaticAssetsList = make(map[string]*webStaticAssets)
 
 	//default settings values
 	app.AppSettingsFilename = ".settings.yml"
+	app.SettingsMaxFileSize = 1024 * 1024
+	app.SettingsMaxDepth = 32
+	app.DotEnvFilename = ".env"
 	if defaultSettings == nil {
-		log.Fatalln("defaultSettings should not be empty")
+		logFatalf("defaultSettings should not be empty")
 	}
 
 	base_settings_type := reflect.TypeFor[AppSettingsBase]()
 
 	if !mttools.IsStructEmbeds(defaultSettings, base_settings_type) {
-		log.Fatalln("settings structure should embed " + base_settings_type.Name())
+		logFatalf("settings structure should embed %s", base_settings_type.Name())
 	}
 
 	app.AppSettings = defaultSettings
@@ -113,16 +212,29 @@ func NewAppBase(defaultSettings interface{}) *AppBase {
 	app.baseSettings = v.FieldByName(base_settings_type.Name()).Addr().Interface().(*AppSettingsBase)
 
 	app.baseSettings.checkDefaultValues(&AppSettingsBase{
-		WebserverHostname:   "localhost",
-		WebserverPort:       15115,
-		ServiceName:         app.ExecutableName,
-		ServiceUser:         "www-data",
-		ServiceGroup:        "www-data",
-		InitialRootPassword: mttools.RandomString(20),
+		WebserverHostname:        "localhost",
+		WebserverPort:            15115,
+		WebserverReadTimeoutSec:  20,
+		WebserverWriteTimeoutSec: 10,
+		WebserverIdleTimeoutSec:  60,
+		MetricsPath:              MetricsPath,
+		DefaultPageSize:          DefaultPageSize,
+		MaxPageSize:              MaxPageSize,
+		ServiceName:              app.ExecutableName,
+		ServiceUser:              "www-data",
+		ServiceGroup:             "www-data",
+		TlsAutocertCacheDir:      "autocert-cache",
+		DatabaseFile:             dbDefaultFileName,
+		InitialRootPassword:      mttools.RandomString(20),
 	})
 
+	//database uses base settings too
+	DbSchema.settings = app.baseSettings
+
 	//global application base context
 	app.BaseContext, app.appShutdownF = context.WithCancel(context.Background())
+	app.requestsContext, app.cancelRequestsF = context.WithCancel(app.BaseContext)
+	app.shutdownRequested = make(chan struct{})
 
 	//compilation data
 	app.Version = BuildVersion
@@ -136,6 +248,7 @@ func NewAppBase(defaultSettings interface{}) *AppBase {
 	app.AppName = "UNSET_AppName"
 
 	app.ShutdownTimeout = 10 * time.Second
+	app.SettingsFetchTimeout = 10 * time.Second
 
 	//build root cobra cmd
 	app.buildRootCmd()
@@ -161,7 +274,8 @@ func (app *AppBase) Run() {
 
 	//cli application - we just let cobra to do its job
 	if err := app.rootCmd.Execute(); err != nil {
-		log.Fatalln(err)
+		app.reportError(err, map[string]any{"kind": "command", "args": os.Args[1:]})
+		logFatalf("%s", err)
 	}
 }
 
@@ -182,7 +296,21 @@ func (app *AppBase) internalInit() {
 		"Filename or full path bot settings file.",
 	)
 
+	app.rootCmd.PersistentFlags().StringVar(
+		&app.DotEnvFilename,
+		"env-file",
+		app.DotEnvFilename,
+		"Filename or full path of .env file to load environment variables from (optional).",
+	)
+
+	//flags bound to settings fields
+	app.buildSettingsFlags()
+
 	//check app options
+	if err := app.checkSettingsFilename(); err != nil {
+		logFatalf("%s", err)
+	}
+
 	if app.WebApiPathPrefix != "" {
 		// no trailing slashes
 		app.WebApiPathPrefix = strings.TrimSuffix(app.WebApiPathPrefix, "/")
@@ -198,29 +326,121 @@ func (app *AppBase) internalInit() {
 		app.buildVersionCmd(),
 		app.buildInstallCmd(),
 		app.buildUninstallCmd(),
+		app.buildGenUnitCmd(),
 		app.buildInitCmd(),
 		app.buildInfoCmd(),
 		app.buildRunCmd(),
+		app.buildReplayCmd(),
+		app.buildRoutesCmd(),
+		app.buildMigrateCmd(),
+		app.buildDbCmd(),
+		app.buildConfigCmd(),
+		app.buildRotateSecretCmd(),
+		app.buildCheckConnectivityCmd(),
 	)
 
 	if app.License != "" {
 		app.rootCmd.AddCommand(app.buildLicenseCmd())
 	}
 
+	//registered modules
+	if err := app.setupModules(); err != nil {
+		logFatalf("%s", err)
+	}
+
 	if app.BuildCustomCommandsF != nil {
 		app.BuildCustomCommandsF(app.rootCmd)
 	}
+
+	//custom commands flags should not shadow framework's persistent ones
+	if err := checkFlagCollisions(app.rootCmd, map[string]string{}); err != nil {
+		logFatalf("%s", err)
+	}
+}
+
+// Checks settings filename is set, has supported extension and does not point to directory.
+func (app *AppBase) checkSettingsFilename() error {
+	if app.AppSettingsFilename == "" {
+		return errors.New("settings filename is empty")
+	}
+
+	name := app.AppSettingsFilename
+
+	// query string (presigned URL signature etc) is not a part of file name
+	if isRemoteSettings(name) {
+		if parsed, err := url.Parse(name); err == nil {
+			name = parsed.Path
+		}
+	}
+
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext != ".yml" && ext != ".yaml" {
+		return fmt.Errorf("settings file %s should have .yml or .yaml extension", app.AppSettingsFilename)
+	}
+
+	if !isRemoteSettings(app.AppSettingsFilename) && mttools.IsDirExists(app.AppSettingsFilename) {
+		return fmt.Errorf("settings file %s is a directory", app.AppSettingsFilename)
+	}
+
+	return nil
 }
 
 func (app *AppBase) loadSettings() error {
-	if mttools.IsFileExists(app.AppSettingsFilename) {
-		if err := mttools.LoadYamlSettingFromFile(app.AppSettingsFilename, app.AppSettings); err != nil {
+	// randomly generated in NewAppBase(), changes only if set in file
+	generatedRootPassword := app.baseSettings.InitialRootPassword
+	app.settingsWarnings = nil
+	app.settingsOrigins = nil
+
+	// .env values become environment variables for overrides below
+	if err := loadDotEnv(app.DotEnvFilename); err != nil {
+		return err
+	}
+
+	filename := app.AppSettingsFilename
+	origin := "file " + filename
+
+	if isRemoteSettings(filename) {
+		origin = "url " + filename
+
+		var err error
+
+		if filename, err = app.fetchRemoteSettings(app.AppSettingsFilename); err != nil {
+			return err
+		}
+
+		defer os.Remove(filename)
+	}
+
+	if mttools.IsFileExists(filename) {
+		if err := checkSettingsFileLimits(filename, app.SettingsMaxFileSize, app.SettingsMaxDepth); err != nil {
+			return err
+		}
+
+		if err := checkSettingsFilePort(filename); err != nil {
+			return err
+		}
+
+		if err := mttools.LoadYamlSettingFromFile(filename, app.AppSettings); err != nil {
+			return err
+		}
+
+		if err := app.setSettingsFileOrigins(filename, origin); err != nil {
 			return err
 		}
 	} else {
 		return fmt.Errorf("File not found: %s", app.AppSettingsFilename)
 	}
 
+	//environment variables override values from file
+	if err := app.applySettingsEnv(); err != nil {
+		return err
+	}
+
+	//command line flags override values from file
+	if err := app.applySettingsFlags(); err != nil {
+		return err
+	}
+
 	// Settings post-processing
 	app.baseSettings.LoadedFromFile = true
 
@@ -232,6 +452,8 @@ func (app *AppBase) loadSettings() error {
 
 		if app.baseSettings.WebserverCookieSecret == "" {
 			return errors.New("webserver_cookie_secret required in production")
+		} else if app.baseSettings.WebserverCookieSecret == devCookieSecret {
+			return errors.New("webserver_cookie_secret is set to default development secret. Never use it in production")
 		} else if len(app.baseSettings.WebserverCookieSecret) < 32 {
 			return fmt.Errorf(
 				"webserver_cookie_secret should be at least 32 characters long in production. You have %d.",
@@ -242,32 +464,238 @@ func (app *AppBase) loadSettings() error {
 	} else {
 		// or use pre-defined values in DEV
 		if app.baseSettings.BaseUrl == "" {
-			app.baseSettings.BaseUrl = "http://" + app.baseSettings.WebserverHostname +
+			scheme := "http://"
+			if app.baseSettings.tlsEnabled() {
+				scheme = "https://"
+			}
+
+			app.baseSettings.BaseUrl = scheme + app.baseSettings.WebserverHostname +
 				":" + strconv.Itoa(int(app.baseSettings.WebserverPort))
+			app.setSettingsPathOrigin("BaseUrl", SettingsOriginDevDefault)
 		}
 
 		if app.baseSettings.WebserverCookieSecret == "" {
-			app.baseSettings.WebserverCookieSecret = "DEFAULT_DEV_SECRET"
+			app.baseSettings.WebserverCookieSecret = devCookieSecret
+			app.setSettingsPathOrigin("WebserverCookieSecret", SettingsOriginDevDefault)
+			app.AddSettingsWarning("webserver_cookie_secret is not set, default development secret is used")
+		}
+	}
+
+	if app.baseSettings.WebserverCorsAllowCredentials && slices.Contains(app.baseSettings.WebserverCorsAllowedOrigins, "*") {
+		return errors.New("webserver_cors_allow_credentials can not be used with \"*\" in webserver_cors_allowed_origins, list allowed origins explicitly")
+	}
+
+	if (app.baseSettings.TlsCertFile == "") != (app.baseSettings.TlsKeyFile == "") {
+		app.AddSettingsWarning("both tls_cert_file and tls_key_file should be set to serve HTTPS, plain HTTP is used")
+	}
+
+	if app.baseSettings.InitialRootPassword != generatedRootPassword {
+		if app.baseSettings.Production {
+			app.AddSettingsWarning(
+				"SECURITY: initial_root_password is set in settings file in production. " +
+					"Anyone who can read settings file can authenticate as root. Delete it once root password is set.",
+			)
+		} else {
+			app.AddSettingsWarning("initial_root_password is set in settings file. Delete it once root password is set.")
 		}
 	}
 
 	return nil
 }
 
+// Refuses too big or too deeply nested settings files before they are loaded.
+func checkSettingsFileLimits(filename string, maxSize int64, maxDepth int) error {
+	stat, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+
+	if maxSize > 0 && stat.Size() > maxSize {
+		return fmt.Errorf("settings file %s is too big: %d bytes (limit is %d)", filename, stat.Size(), maxSize)
+	}
+
+	if maxDepth <= 0 {
+		return nil
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	var root yaml.Node
+
+	// syntax errors are reported by settings loading
+	if yaml.Unmarshal(data, &root) != nil {
+		return nil
+	}
+
+	if yamlNodeDepth(&root, maxDepth) > maxDepth {
+		return fmt.Errorf("settings file %s is nested too deeply (limit is %d levels)", filename, maxDepth)
+	}
+
+	return nil
+}
+
+// Returns nesting depth of mappings and sequences. Counting stops when depth exceeds limit. Aliases are not followed.
+func yamlNodeDepth(node *yaml.Node, limit int) int {
+	isContainer := node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode
+
+	if isContainer {
+		if limit == 0 {
+			return 1 // exceeds limit already, no need to go deeper
+		}
+
+		limit--
+	}
+
+	depth := 0
+
+	for _, child := range node.Content {
+		if d := yamlNodeDepth(child, limit); d > depth {
+			depth = d
+		}
+	}
+
+	if isContainer {
+		depth++
+	}
+
+	return depth
+}
+
+// Checks webserver_port value range in settings file. It is checked before settings loading
+// to report clear message instead of YAML type conversion error for values not fitting uint16.
+func checkSettingsFilePort(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	var portCheck struct {
+		WebserverPort *int64 `yaml:"webserver_port"`
+	}
+
+	// syntax errors are reported by settings loading
+	if yaml.Unmarshal(data, &portCheck) != nil || portCheck.WebserverPort == nil {
+		return nil
+	}
+
+	if port := *portCheck.WebserverPort; port < 1 || port > 65535 {
+		return fmt.Errorf("webserver_port should be in 1-65535 range, %d given", port)
+	}
+
+	return nil
+}
+
+// Adds non-fatal settings issue ("you should fix this") to be reported at startup.
+// Can be called by app to report its own settings issues (from PreCmdF for example).
+func (app *AppBase) AddSettingsWarning(message string) {
+	app.settingsWarnings = append(app.settingsWarnings, message)
+}
+
+// Returns non-fatal settings issues found during settings loading.
+func (app *AppBase) SettingsWarnings() []string {
+	return app.settingsWarnings
+}
+
+// Logs settings warnings (if any) to be noticed
+func (app *AppBase) logSettingsWarnings() {
+	if len(app.settingsWarnings) == 0 {
+		return
+	}
+
+	logWarnf("================================")
+	logWarnf("SETTINGS WARNINGS (%d):", len(app.settingsWarnings))
+
+	for _, message := range app.settingsWarnings {
+		logWarnf("  - %s", message)
+	}
+
+	logWarnf("================================")
+}
+
 func (app *AppBase) saveSettings(comment string) error {
-	return mttools.SaveYamlSettingToFile(app.AppSettingsFilename, comment, app.AppSettings)
+	return saveYamlSettingsWithDocs(app.AppSettingsFilename, comment, app.AppSettings)
+}
+
+// Returns application settings as concrete settings type T (struct type passed to NewAppBase()).
+// Panics if T does not match settings type.
+func Settings[T any](app *AppBase) *T {
+	settings, ok := app.AppSettings.(*T)
+
+	if !ok {
+		logPanicf("Settings: settings type is %T, not *%s\n", app.AppSettings, reflect.TypeFor[T]().String())
+	}
+
+	return settings
 }
 
 func (app *AppBase) printSettings() {
 	mttools.PrintYamlSettings(app.AppSettings)
 }
 
+// Registers version of additional component (sub-service, plugin, etc.) bundled with app.
+// Components versions are printed by `version --verbose` and `info` commands.
+func (app *AppBase) AddVersionComponent(name, version string) *AppBase {
+	app.versionComponents = append(app.versionComponents, versionComponent{name: name, version: version})
+
+	return app //for method chaining
+}
+
 func (app *AppBase) ApiHandler(path string, handler ApiRequestHandler) *AppBase {
+	return app.ApiHandlerWithMiddleware(path, handler)
+}
+
+// Sets HTTP methods accepted by API route (like http.MethodGet for read-only endpoints). Requests with
+// other methods get 405 reply. Routes without methods set accept POST (and GET if WebApiEnableGet is set).
+func (app *AppBase) ApiMethods(path string, methods ...string) *AppBase {
+	list := make([]string, len(methods))
+
+	for i, method := range methods {
+		list[i] = strings.ToUpper(method)
+	}
+
+	app.webApiMethodList[path] = list
+
+	return app //for method chaining
+}
+
+// Registers API handler with middleware to run in given order before it (admin-only checks, rate limits etc).
+// Middleware aborting request (c.Abort(), c.AbortWithStatus() etc) stops the chain, handler is not called.
+// Middleware are called one by one before handler, they are not a gin handlers chain (all API paths share
+// one catch-all route): c.Next() returns right away without calling handler, so code after c.Next() runs
+// before handler. Use them for checks only, wrap handler itself to run code around it (timing, reply changes).
+func (app *AppBase) ApiHandlerWithMiddleware(path string, handler ApiRequestHandler, mw ...gin.HandlerFunc) *AppBase {
 	app.webApiHandlerList[path] = handler
 
+	if len(mw) > 0 {
+		app.webApiMiddlewareList[path] = mw
+	} else {
+		delete(app.webApiMiddlewareList, path)
+	}
+
 	return app //for method chaining
 }
 
+// Reports graceful shutdown progress to OnShutdownProgressF callback (if set).
+func (app *AppBase) shutdownProgress(stage string) {
+	if app.OnShutdownProgressF != nil {
+		app.OnShutdownProgressF(stage)
+	}
+}
+
+// Returns subcommand being executed. Can be used in PreRunF, PostRunF and other callbacks
+// to read command flags. Returns nil before command execution is started.
+func (app *AppBase) CurrentCmd() *cobra.Command {
+	return app.currentCmd
+}
+
+// Returns arguments of subcommand being executed.
+func (app *AppBase) CurrentArgs() []string {
+	return app.currentArgs
+}
+
 func (app *AppBase) IsDevMode() bool {
 	return app.Version == DEV_MODE_LABEL // && false //uncomment to debug production mode
 }
@@ -0,0 +1,168 @@
+package goapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// External dependency check for `check-connectivity` command
+type connectivityCheck struct {
+	name    string
+	checkF  func(ctx context.Context) error
+	skipped string // reason to report instead of running check
+}
+
+// Adds app-specific check to `check-connectivity` command (SMTP server login, third-party API token
+// etc). fn should respect ctx deadline.
+func (app *AppBase) AddConnectivityCheck(name string, fn func(ctx context.Context) error) *AppBase {
+	app.connectivityCheckList = append(app.connectivityCheckList, connectivityCheck{name: name, checkF: fn})
+
+	return app //for method chaining
+}
+
+// Builds list of checks: database, tracing collector, connectivity_check_targets setting and ones
+// added with AddConnectivityCheck().
+func (app *AppBase) connectivityChecks() []connectivityCheck {
+	var list []connectivityCheck
+
+	if DbSchema.appModelCount() > 0 {
+		list = append(list, connectivityCheck{
+			name:   "database " + DbSchema.driver() + " " + DbSchema.name(),
+			checkF: checkDatabaseConnectivity,
+		})
+	} else {
+		list = append(list, connectivityCheck{name: "database", skipped: "no models registered"})
+	}
+
+	if endpoint := app.baseSettings.TracingOtlpEndpoint; endpoint != "" {
+		list = append(list, connectivityCheck{
+			name:   "tracing collector " + endpoint,
+			checkF: func(ctx context.Context) error { return checkTargetConnectivity(ctx, endpoint) },
+		})
+	}
+
+	for _, target := range app.baseSettings.ConnectivityCheckTargets {
+		list = append(list, connectivityCheck{
+			name:   target,
+			checkF: func(ctx context.Context) error { return checkTargetConnectivity(ctx, target) },
+		})
+	}
+
+	return append(list, app.connectivityCheckList...)
+}
+
+// Runs all checks one by one printing result and latency for each of them. Returns error if any check failed.
+func (app *AppBase) runConnectivityChecks(timeout time.Duration) error {
+	failed := 0
+
+	for _, check := range app.connectivityChecks() {
+		if check.skipped != "" {
+			fmt.Printf("SKIP  %s: %s\n", check.name, check.skipped)
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(app.BaseContext, timeout)
+		start := time.Now()
+
+		err := callConnectivityCheck(ctx, check.checkF)
+		latency := time.Since(start).Round(time.Millisecond)
+
+		cancel()
+
+		if err != nil {
+			failed++
+			fmt.Printf("FAIL  %s (%s): %s\n", check.name, latency, err.Error())
+		} else {
+			fmt.Printf("PASS  %s (%s)\n", check.name, latency)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d connectivity check(s) failed", failed)
+	}
+
+	return nil
+}
+
+// Panicking app check is reported as failed one.
+func callConnectivityCheck(ctx context.Context, checkF func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("check panicked: %v", r)
+		}
+	}()
+
+	return checkF(ctx)
+}
+
+func checkDatabaseConnectivity(ctx context.Context) error {
+	if DbSchema.Db() == nil {
+		if err := DbSchema.connect(false); err != nil {
+			return err
+		}
+
+		defer DbSchema.Close()
+	}
+
+	sqlDB, err := DbSchema.Db().DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.PingContext(ctx)
+}
+
+// Checks connectivity_check_targets item: http(s) URL should respond with non-5xx status,
+// "tcp://host:port" or "host:port" should accept TCP connection.
+func checkTargetConnectivity(ctx context.Context, target string) error {
+	lower := strings.ToLower(target)
+
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") {
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err != nil {
+			return err
+		}
+
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			return err
+		}
+		defer response.Body.Close()
+
+		if response.StatusCode >= http.StatusInternalServerError {
+			return errors.New("server responded with " + response.Status)
+		}
+
+		return nil
+	}
+
+	address := target
+
+	if strings.Contains(target, "://") {
+		u, err := url.Parse(target)
+		if err != nil {
+			return err
+		}
+
+		if u.Scheme != "tcp" {
+			return fmt.Errorf("unsupported scheme '%s' (should be http, https or tcp)", u.Scheme)
+		}
+
+		address = u.Host
+	}
+
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
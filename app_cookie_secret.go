@@ -0,0 +1,131 @@
+package goapp
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+
+	"github.com/gin-contrib/sessions/cookie"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	cookieSecretKey          = "webserver_cookie_secret"
+	cookiePreviousSecretsKey = "webserver_cookie_previous_secrets"
+	cookieSecretBytes        = 32 // random bytes in generated secret (hex encoded)
+)
+
+// Creates session cookie store. Cookies are signed with primary secret, previous secrets are
+// accepted for cookies signed before rotation.
+func (app *AppBase) newSessionStore() cookie.Store {
+	keyPairs := [][]byte{[]byte(app.baseSettings.WebserverCookieSecret), nil}
+
+	for _, secret := range app.baseSettings.WebserverCookiePreviousSecrets {
+		if secret != "" {
+			keyPairs = append(keyPairs, []byte(secret), nil) //signing only, no encryption
+		}
+	}
+
+	return cookie.NewStore(keyPairs...)
+}
+
+// Generates new cookie secret and moves current one to previous secrets list (keeping not more than keep
+// previous secrets) directly in settings file, so other values and comments are left untouched.
+func rotateCookieSecret(filename string, keep int) error {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return errors.New("settings file " + filename + " is not a YAML mapping")
+	}
+
+	root := doc.Content[0]
+
+	var previous []string
+
+	if node := yamlMappingValue(root, cookiePreviousSecretsKey); node != nil && node.Kind == yaml.SequenceNode {
+		if err := node.Decode(&previous); err != nil {
+			return err
+		}
+	}
+
+	if node := yamlMappingValue(root, cookieSecretKey); node != nil && node.Value != "" && node.Value != devCookieSecret {
+		previous = append([]string{node.Value}, previous...)
+	}
+
+	previous = previous[:min(len(previous), max(keep, 0))]
+
+	var previousNode yaml.Node
+	if err := previousNode.Encode(previous); err != nil {
+		return err
+	}
+
+	secret, err := newCookieSecret()
+	if err != nil {
+		return err
+	}
+
+	setYamlMappingValue(root, cookieSecretKey, &yaml.Node{
+		Kind:  yaml.ScalarNode,
+		Tag:   "!!str",
+		Value: secret,
+	})
+	setYamlMappingValue(root, cookiePreviousSecretsKey, &previousNode)
+
+	data, err = yaml.Marshal(&doc)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, data, info.Mode().Perm())
+}
+
+// Generates cookie secret from cryptographically strong random bytes.
+func newCookieSecret() (string, error) {
+	buf := make([]byte, cookieSecretBytes)
+
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+// Returns value node for key in mapping node (nil if there is no such key).
+func yamlMappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// Replaces value node for key in mapping node or appends key if there is no such one.
+func setYamlMappingValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			//keep comments
+			value.LineComment = mapping.Content[i+1].LineComment
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
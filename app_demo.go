@@ -0,0 +1,58 @@
+package goapp
+
+import (
+	"net"
+	"os"
+	"strconv"
+)
+
+// in-memory sqlite database shared by all connections of pool
+const dbDemoDsn = "file:goapp-demo?mode=memory&cache=shared"
+
+// Runs webserver ('run' command) as ephemeral demo instance: settings file is not required (defaults
+// are used), database is in-memory sqlite and webserver listens to random free port. Everything is lost on exit.
+// Command line arguments are ignored.
+func (app *AppBase) RunDemo() {
+	app.demoMode = true
+
+	app.internalInit()
+	app.rootCmd.SetArgs([]string{"run"})
+
+	if err := app.rootCmd.Execute(); err != nil {
+		app.reportError(err, map[string]any{"kind": "command", "args": os.Args[1:]})
+		logFatalf("%s", err)
+	}
+}
+
+// Sets demo mode settings instead of loading settings file.
+func (app *AppBase) applyDemoSettings() {
+	settings := app.baseSettings
+
+	settings.Production = false
+	settings.WebserverPort = 0 // random free port
+	settings.DatabaseDriver = dbDriverSqlite
+	settings.DatabaseFile = dbDemoDsn
+
+	if settings.WebserverCookieSecret == "" {
+		settings.WebserverCookieSecret = devCookieSecret
+	}
+
+	for _, path := range []string{"Production", "WebserverPort", "DatabaseDriver", "DatabaseFile", "WebserverCookieSecret"} {
+		app.setSettingsPathOrigin(path, SettingsOriginDemo)
+	}
+
+	logInfof("DEMO mode: no settings file, in-memory database. All data is lost on exit.")
+}
+
+// Sets real port and base url when demo webserver is listening already.
+func (app *AppBase) demoListening(listener net.Listener) {
+	if addr, ok := listener.Addr().(*net.TCPAddr); ok {
+		app.baseSettings.WebserverPort = uint16(addr.Port)
+	}
+
+	app.baseSettings.BaseUrl = "http://" + app.baseSettings.WebserverHostname +
+		":" + strconv.Itoa(int(app.baseSettings.WebserverPort))
+	app.setSettingsPathOrigin("BaseUrl", SettingsOriginDemo)
+
+	logInfof("DEMO instance URL: %s", app.baseSettings.BaseUrl)
+}
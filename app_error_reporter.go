@@ -0,0 +1,75 @@
+package goapp
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Forwards error to ErrorReporterF (if set). Reporter panics are logged and do not affect app.
+func (app *AppBase) reportError(err error, ctx map[string]any) {
+	if app.ErrorReporterF == nil || err == nil {
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			logErrorf("ErrorReporterF panic: %v", r)
+		}
+	}()
+
+	app.ErrorReporterF(err, ctx)
+}
+
+// set by reportPanic(), so errorReporterMiddleware does not report the same request again
+var panicReportedContextKey = NewContextKey[bool]("panic-reported")
+
+// Reports web request panic with stack trace. Should be called from recovery handler.
+func (app *AppBase) reportPanic(c *gin.Context, recovered any) {
+	panicReportedContextKey.Set(c, true)
+
+	if app.ErrorReporterF == nil {
+		return
+	}
+
+	err, ok := recovered.(error)
+	if !ok {
+		err = fmt.Errorf("panic: %v", recovered)
+	}
+
+	app.reportError(err, map[string]any{
+		"kind":   "panic",
+		"method": c.Request.Method,
+		"path":   c.Request.URL.Path,
+		"stack":  string(debug.Stack()),
+	})
+}
+
+// Middleware reporting requests finished with 5xx status (when ReportServerErrors is enabled).
+func (app *AppBase) errorReporterMiddleware(c *gin.Context) {
+	c.Next()
+
+	// panics are reported by recovery handler already
+	if panicReported, _ := panicReportedContextKey.Get(c); panicReported {
+		return
+	}
+
+	if status := c.Writer.Status(); status >= http.StatusInternalServerError {
+		ctx := map[string]any{
+			"kind":   "server_error",
+			"method": c.Request.Method,
+			"path":   c.Request.URL.Path,
+			"status": status,
+		}
+
+		err := fmt.Errorf("%s %s: %d %s", c.Request.Method, c.Request.URL.Path, status, http.StatusText(status))
+
+		if len(c.Errors) > 0 {
+			ctx["errors"] = c.Errors.Errors()
+		}
+
+		app.reportError(err, ctx)
+	}
+}
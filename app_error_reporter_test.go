@@ -0,0 +1,31 @@
+package goapp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestErrorReporterAbortedServerErrors(t *testing.T) {
+	app := NewAppBase(&testShutdownSettings{})
+
+	reported := map[string]int{}
+	app.ErrorReporterF = func(err error, ctx map[string]any) {
+		reported[ctx["kind"].(string)]++
+	}
+
+	engine := gin.New()
+	engine.Use(gin.CustomRecovery(app.webErrorPagesRecovery), app.errorReporterMiddleware)
+	engine.GET("/aborted", func(c *gin.Context) { c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{}) })
+	engine.GET("/panic", func(c *gin.Context) { panic("boom") })
+
+	for _, path := range []string{"/aborted", "/panic"} {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if reported["server_error"] != 1 || reported["panic"] != 1 {
+		t.Errorf("expected one server error and one panic report, got %v", reported)
+	}
+}
@@ -0,0 +1,91 @@
+package goapp
+
+import (
+	"context"
+	"sync"
+)
+
+// Event handler. ctx is cancelled when app is being shutdown.
+type EventHandler func(ctx context.Context, payload any)
+
+type eventSubscriber struct {
+	handler EventHandler
+	async   bool
+}
+
+// In-process event bus
+type eventBus struct {
+	mu          sync.RWMutex
+	subscribers map[string][]eventSubscriber // event name => subscribers
+}
+
+// Subscribes handler to event. Handler is called synchronously by Publish().
+func (app *AppBase) Subscribe(event string, fn EventHandler) *AppBase {
+	app.events.add(event, eventSubscriber{handler: fn})
+
+	return app //for method chaining
+}
+
+// Subscribes handler to event. Handler is called in separate goroutine, Publish() does not wait for it.
+// Graceful shutdown waits for running handlers.
+func (app *AppBase) SubscribeAsync(event string, fn EventHandler) *AppBase {
+	app.events.add(event, eventSubscriber{handler: fn, async: true})
+
+	return app //for method chaining
+}
+
+// Delivers payload to all event subscribers. Synchronous handlers are called in order of subscription
+// before Publish() returns. Nothing is delivered if app is being shutdown already.
+func (app *AppBase) Publish(ctx context.Context, event string, payload any) {
+	if app.BaseContext.Err() != nil {
+		return // shutting down
+	}
+
+	for _, subscriber := range app.events.list(event) {
+		if subscriber.async {
+			// async handlers outlive publisher, so keep ctx values only
+			handlerCtx, cancel := app.eventContext(context.WithoutCancel(ctx))
+
+			app.backgroundWg.Add(1)
+
+			go func() {
+				defer app.backgroundWg.Done()
+				defer cancel()
+				subscriber.handler(handlerCtx, payload)
+			}()
+		} else {
+			handlerCtx, cancel := app.eventContext(ctx)
+			subscriber.handler(handlerCtx, payload)
+			cancel()
+		}
+	}
+}
+
+// Returns ctx copy cancelled on app shutdown (BaseContext cancellation) too.
+func (app *AppBase) eventContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	handlerCtx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(app.BaseContext, cancel)
+
+	return handlerCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
+func (bus *eventBus) add(event string, subscriber eventSubscriber) {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+
+	if bus.subscribers == nil {
+		bus.subscribers = make(map[string][]eventSubscriber)
+	}
+
+	bus.subscribers[event] = append(bus.subscribers[event], subscriber)
+}
+
+func (bus *eventBus) list(event string) []eventSubscriber {
+	bus.mu.RLock()
+	defer bus.mu.RUnlock()
+
+	return bus.subscribers[event]
+}
@@ -0,0 +1,102 @@
+package goapp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Conventional paths of health check endpoints to set in health_check_path, liveness_check_path and
+// readiness_check_path settings (endpoints are disabled by default)
+const (
+	HealthzPath = "/healthz"
+	LivezPath   = "/livez"
+	ReadyzPath  = "/readyz"
+)
+
+// timeout for single health check
+const healthCheckTimeout = 5 * time.Second
+
+// Adds app-specific value (queue depth, last sync time etc) to health endpoint response
+// "details" object. fn is called on every health request so it should be fast.
+func (app *AppBase) RegisterHealthDetail(name string, fn func() any) *AppBase {
+	app.healthDetailList[name] = fn
+
+	return app //for method chaining
+}
+
+// Checks app is able to serve requests: database (if opened) should respond to ping.
+func (app *AppBase) checkHealth() error {
+	if DbSchema.Db() != nil {
+		sqlDB, err := DbSchema.Db().DB()
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(app.BaseContext, healthCheckTimeout)
+		defer cancel()
+
+		if err := sqlDB.PingContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Collects registered health details. Panicking detail function gets error message as value.
+func (app *AppBase) healthDetails() map[string]any {
+	details := make(map[string]any, len(app.healthDetailList))
+
+	for name, fn := range app.healthDetailList {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					details[name] = fmt.Sprintf("ERROR: %v", r)
+				}
+			}()
+
+			details[name] = fn()
+		}()
+	}
+
+	return details
+}
+
+func (app *AppBase) healthGinHandler(c *gin.Context) {
+	response := gin.H{"status": "ok"}
+	status := http.StatusOK
+
+	if err := app.checkHealth(); err != nil {
+		response["status"] = "error"
+		response["error"] = err.Error()
+		status = http.StatusServiceUnavailable
+	}
+
+	if len(app.healthDetailList) > 0 {
+		response["details"] = app.healthDetails()
+	}
+
+	c.JSON(status, response)
+}
+
+func (app *AppBase) livenessGinHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func (app *AppBase) readinessGinHandler(c *gin.Context) {
+	if !app.ready.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "error", "error": "not ready"})
+		return
+	}
+
+	if err := app.checkHealth(); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
@@ -0,0 +1,225 @@
+package goapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/logger"
+)
+
+// log_format setting values
+const (
+	LogFormatText = "text"
+	LogFormatJson = "json"
+)
+
+// log_level setting values
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+type logLevel int32
+
+const (
+	logLevelDebug logLevel = iota
+	logLevelInfo
+	logLevelWarn
+	logLevelError
+)
+
+var logLevelNames = map[string]logLevel{
+	"":            logLevelInfo,
+	LogLevelDebug: logLevelDebug,
+	LogLevelInfo:  logLevelInfo,
+	LogLevelWarn:  logLevelWarn,
+	LogLevelError: logLevelError,
+}
+
+func (level logLevel) String() string {
+	return [...]string{LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError}[level]
+}
+
+var (
+	// messages below this level are not logged (see log_level setting)
+	minLogLevel atomic.Int32
+
+	// set in JSON mode to write entries with explicit level instead of guessed one
+	jsonLogOutput atomic.Pointer[jsonLogWriter]
+)
+
+func init() {
+	minLogLevel.Store(int32(logLevelInfo))
+}
+
+// Leveled logging for framework messages. Standard logger is used for output, level is marked
+// with "[WARN]" like prefix in text format and with level field in JSON format.
+func logf(level logLevel, format string, args ...any) {
+	if int32(level) < minLogLevel.Load() {
+		return
+	}
+
+	msg := fmt.Sprintf(format, args...)
+
+	if writer := jsonLogOutput.Load(); writer != nil {
+		writer.writeEntry(level.String(), msg, nil)
+		return
+	}
+
+	// info messages are the most common ones, so only other levels are marked in text format
+	if level != logLevelInfo {
+		msg = "[" + strings.ToUpper(level.String()) + "] " + msg
+	}
+
+	log.Output(3, msg)
+}
+
+func logDebugf(format string, args ...any) { logf(logLevelDebug, format, args...) }
+func logInfof(format string, args ...any)  { logf(logLevelInfo, format, args...) }
+func logWarnf(format string, args ...any)  { logf(logLevelWarn, format, args...) }
+func logErrorf(format string, args ...any) { logf(logLevelError, format, args...) }
+
+// Logs error and exits (like log.Fatalf() but with error level).
+func logFatalf(format string, args ...any) {
+	logf(logLevelError, format, args...)
+	os.Exit(1)
+}
+
+// Logs error and panics with message (like log.Panicf() but with error level).
+func logPanicf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+
+	logf(logLevelError, "%s", msg)
+	panic(msg)
+}
+
+// Maps log level to gorm logger level (SQL queries are logged with log_sql setting or in debug level).
+func gormLogLevel() logger.LogLevel {
+	switch logLevel(minLogLevel.Load()) {
+	case logLevelDebug:
+		return logger.Info
+	case logLevelError:
+		return logger.Error
+	default:
+		return logger.Warn
+	}
+}
+
+// Writes every standard logger line as JSON object (one per line): time, level, msg.
+// Framework messages get explicit level (see logf()), other standard logger lines are logged with
+// info level as standard logger has no levels.
+type jsonLogWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (w *jsonLogWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimRight(string(p), "\r\n")
+
+	if err := w.writeEntry(logLevelInfo.String(), msg, nil); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func (w *jsonLogWriter) writeEntry(level, msg string, fields map[string]any) error {
+	entry := make(map[string]any, len(fields)+3)
+
+	for name, value := range fields {
+		entry[name] = value
+	}
+
+	entry["time"] = time.Now().Format(time.RFC3339Nano)
+	entry["level"] = level
+	entry["msg"] = strings.Trim(msg, "\r\n")
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, err = w.out.Write(append(data, '\n'))
+
+	return err
+}
+
+// Applies log_format and log_level settings. Called after settings are loaded.
+func (app *AppBase) setupLogging() error {
+	level, ok := logLevelNames[app.baseSettings.LogLevel]
+	if !ok {
+		return fmt.Errorf("unknown log_level '%s' (should be debug, info, warn or error)", app.baseSettings.LogLevel)
+	}
+
+	minLogLevel.Store(int32(level))
+
+	switch app.baseSettings.LogFormat {
+	case "", LogFormatText:
+		return nil
+
+	case LogFormatJson:
+		writer := &jsonLogWriter{out: os.Stderr}
+		jsonLogOutput.Store(writer)
+
+		log.SetFlags(0) // time is a JSON field
+		log.SetOutput(writer)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown log_format '%s' (should be '%s' or '%s')", app.baseSettings.LogFormat, LogFormatText, LogFormatJson)
+	}
+}
+
+// Logs every web request with single line: method, path, status, duration, client IP and response size.
+// Fields are separate JSON fields in JSON log format. 5xx responses are logged with error level.
+func (app *AppBase) requestLoggerMiddleware(c *gin.Context) {
+	start := time.Now()
+	path := c.Request.URL.Path // no query string, it could contain tokens
+
+	c.Next()
+
+	duration := time.Since(start)
+	status := c.Writer.Status()
+	size := max(c.Writer.Size(), 0) // -1 if nothing was written
+
+	level := logLevelInfo
+	if status >= http.StatusInternalServerError {
+		level = logLevelError
+	}
+
+	if int32(level) < minLogLevel.Load() {
+		return
+	}
+
+	if writer := jsonLogOutput.Load(); writer != nil {
+		writer.writeEntry(level.String(), "request", map[string]any{
+			"method":      c.Request.Method,
+			"path":        path,
+			"status":      status,
+			"duration_ms": float64(duration.Microseconds()) / 1000,
+			"client_ip":   c.ClientIP(),
+			"size":        size,
+		})
+
+		return
+	}
+
+	log.Printf(
+		"request method=%s path=%q status=%d duration=%s client_ip=%s size=%d\n",
+		c.Request.Method, path, status, duration.Round(time.Microsecond), c.ClientIP(), size,
+	)
+}
@@ -0,0 +1,52 @@
+package goapp
+
+import (
+	"fmt"
+)
+
+type appModule struct {
+	name  string
+	setup func(app *AppBase) error
+}
+
+// registered modules in registration order
+var appModuleList []appModule
+
+// Registers optional app module. Usually called from module package init() function.
+// setup is called for every module during app initialization (in order of registration)
+// and can add API handlers, commands, callbacks etc.
+func RegisterModule(name string, setup func(app *AppBase) error) {
+	if setup == nil {
+		logPanicf("module '%s' setup function should not be nil", name)
+	}
+
+	for _, module := range appModuleList {
+		if module.name == name {
+			logPanicf("module '%s' is already registered", name)
+		}
+	}
+
+	appModuleList = append(appModuleList, appModule{name: name, setup: setup})
+}
+
+// Returns names of registered modules in order of registration.
+func ModuleNames() []string {
+	names := make([]string, 0, len(appModuleList))
+
+	for _, module := range appModuleList {
+		names = append(names, module.name)
+	}
+
+	return names
+}
+
+// Calls setup functions of all registered modules
+func (app *AppBase) setupModules() error {
+	for _, module := range appModuleList {
+		if err := module.setup(app); err != nil {
+			return fmt.Errorf("module '%s' setup error: %w", module.name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package goapp
+
+import (
+	"errors"
+)
+
+// Checks webserver is not started as root before it starts listening.
+func (app *AppBase) checkRunAsRoot() error {
+	if !isRootUser() || app.baseSettings.WebserverDropPrivileges {
+		return nil
+	}
+
+	if app.baseSettings.WebserverRefuseRoot {
+		return errors.New("refusing to run webserver as root (see webserver_refuse_root and webserver_drop_privileges settings)")
+	}
+
+	logWarnf("SECURITY WARNING: webserver is running as root. Run it as service_user or set webserver_drop_privileges.")
+
+	return nil
+}
+
+// Switches process to service user and group when started as root (after privileged port is bound).
+func (app *AppBase) dropRootPrivileges() error {
+	if !isRootUser() || !app.baseSettings.WebserverDropPrivileges {
+		return nil
+	}
+
+	if err := dropPrivileges(app.baseSettings.ServiceUser, app.baseSettings.ServiceGroup); err != nil {
+		return errors.New("can not drop root privileges: " + err.Error())
+	}
+
+	logInfof("Root privileges dropped, running as %s:%s", app.baseSettings.ServiceUser, app.baseSettings.ServiceGroup)
+
+	return nil
+}
@@ -0,0 +1,59 @@
+//go:build !windows
+
+package goapp
+
+import (
+	"errors"
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+func isRootUser() bool {
+	return os.Geteuid() == 0
+}
+
+// Sets process group and user (for all threads). Supplementary groups are cleared.
+func dropPrivileges(userName, groupName string) error {
+	u, err := user.Lookup(userName)
+	if err != nil {
+		return err
+	}
+
+	uid, err := strconv.Atoi(u.Uid)
+	if err != nil {
+		return err
+	}
+
+	gid, err := strconv.Atoi(u.Gid)
+	if err != nil {
+		return err
+	}
+
+	if groupName != "" {
+		g, err := user.LookupGroup(groupName)
+		if err != nil {
+			return err
+		}
+
+		if gid, err = strconv.Atoi(g.Gid); err != nil {
+			return err
+		}
+	}
+
+	if uid == 0 {
+		return errors.New("service user " + userName + " is root")
+	}
+
+	// group first, setgid is not permitted after root is gone
+	if err := syscall.Setgroups([]int{}); err != nil {
+		return err
+	}
+
+	if err := syscall.Setgid(gid); err != nil {
+		return err
+	}
+
+	return syscall.Setuid(uid)
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package goapp
+
+import "errors"
+
+// there is no root user in Windows
+func isRootUser() bool {
+	return false
+}
+
+func dropPrivileges(userName, groupName string) error {
+	return errors.New("not supported on Windows")
+}
@@ -0,0 +1,39 @@
+package goapp
+
+import (
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Path of dev-mode runtime stats endpoint
+const RuntimeStatsPath = "/debug/runtime"
+
+// Returns quick runtime health snapshot: goroutines count, memory and GC stats.
+func (app *AppBase) RuntimeStats() map[string]any {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	var lastGcPause time.Duration
+	if memStats.NumGC > 0 {
+		lastGcPause = time.Duration(memStats.PauseNs[(memStats.NumGC+255)%256])
+	}
+
+	return map[string]any{
+		"goroutines":     runtime.NumGoroutine(),
+		"num_cpu":        runtime.NumCPU(),
+		"heap_alloc":     memStats.HeapAlloc,
+		"heap_sys":       memStats.HeapSys,
+		"heap_objects":   memStats.HeapObjects,
+		"num_gc":         memStats.NumGC,
+		"last_gc_pause":  lastGcPause.String(),
+		"total_gc_pause": time.Duration(memStats.PauseTotalNs).String(),
+		"uptime":         app.Uptime().Round(time.Second).String(),
+	}
+}
+
+func (app *AppBase) runtimeStatsGinHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, app.RuntimeStats())
+}
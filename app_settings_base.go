@@ -7,17 +7,73 @@ type AppSettingsBase struct {
 
 	BaseUrl string `yaml:"base_url" yaml_comment:"Base external site URL (with protocol and port, no trailing slash)"`
 
-	WebserverHostname     string `yaml:"webserver_hostname" yaml_comment:"Webserver hostname"`
-	WebserverPort         uint16 `yaml:"webserver_port" yaml_comment:"Webserver port number"`
-	WebserverCookieSecret string `yaml:"webserver_cookie_secret" yaml_comment:"Secret string to encrypt cookies. Required in Production mode."`
+	WebserverHostname              string          `yaml:"webserver_hostname" yaml_comment:"Webserver hostname"`
+	WebserverPort                  uint16          `yaml:"webserver_port" yaml_comment:"Webserver port number"`
+	WebserverCookieSecret          string          `yaml:"webserver_cookie_secret" yaml_comment:"Secret string to encrypt cookies. Required in Production mode."`
+	WebserverCookiePreviousSecrets []string        `yaml:"webserver_cookie_previous_secrets" yaml_comment:"Previous cookie secrets. Sessions signed with them are still accepted (see 'rotate-secret' command)."`
+	WebserverMaxHeaderBytes        int             `yaml:"webserver_max_header_bytes" yaml_comment:"Maximum size of request headers in bytes. 0 = default (1 MB)."`
+	WebserverCacheRoutes           map[string]uint `yaml:"webserver_cache_routes" yaml_comment:"GET routes to cache responses for in memory (route path => TTL in seconds). Cache keeps up to 10000 responses (64 MB). Responses are shared by all clients, so list user-independent routes only. Requests with Authorization header or session cookie and responses with Vary header are not cached."`
+	WebserverDisableKeepAlives     bool            `yaml:"webserver_disable_keep_alives" yaml_comment:"Disable HTTP keep-alives (close connection after each request). Needed behind some L4 load balancers."`
+
+	WebserverReadTimeoutSec  uint `yaml:"webserver_read_timeout_sec" yaml_comment:"Seconds to read whole request (including body) in. 0 = no timeout."`
+	WebserverWriteTimeoutSec uint `yaml:"webserver_write_timeout_sec" yaml_comment:"Seconds to write response in (counted from request headers read). Increase for large downloads and slow clients. 0 = no timeout."`
+	WebserverIdleTimeoutSec  uint `yaml:"webserver_idle_timeout_sec" yaml_comment:"Seconds to keep idle keep-alive connection open. 0 = no timeout."`
+
+	WebserverCorsAllowedOrigins   []string `yaml:"webserver_cors_allowed_origins" yaml_comment:"Origins (like https://app.example.com) allowed to make cross-origin requests. \"*\" = any origin. Empty = CORS disabled."`
+	WebserverCorsAllowedMethods   []string `yaml:"webserver_cors_allowed_methods" yaml_comment:"Methods allowed for cross-origin requests. Empty = GET, HEAD, POST."`
+	WebserverCorsAllowCredentials bool     `yaml:"webserver_cors_allow_credentials" yaml_comment:"Allow cross-origin requests with cookies (session). Origins should be listed explicitly (\"*\" is not allowed with it)."`
+
+	WebserverCaptureFile string `yaml:"webserver_capture_file" yaml_comment:"File to record requests resulted in 5xx errors to (for 'replay' command). Sensitive headers and fields are redacted. Empty = disabled."`
+
+	HealthCheckPath    string `yaml:"health_check_path" yaml_comment:"Path of health check endpoint (200 if app is up and database is reachable, 503 otherwise), like /healthz. Empty = disabled."`
+	LivenessCheckPath  string `yaml:"liveness_check_path" yaml_comment:"Path of liveness endpoint (always 200 while process serves requests), like /livez. Empty = disabled."`
+	ReadinessCheckPath string `yaml:"readiness_check_path" yaml_comment:"Path of readiness endpoint (200 once startup is finished and database is reachable, 503 during startup and shutdown), like /readyz. Empty = disabled."`
+
+	DefaultPageSize int `yaml:"default_page_size" yaml_comment:"Page size for lists if client does not request one."`
+	MaxPageSize     int `yaml:"max_page_size" yaml_comment:"Maximum page size client can request for lists (bigger ones are reduced)."`
+
+	WebApiIdempotencyTtl uint `yaml:"webapi_idempotency_ttl" yaml_comment:"Seconds to keep API responses for Idempotency-Key header replays. 0 = disabled."`
+
+	WebApiRateLimit float64 `yaml:"webapi_rate_limit" yaml_comment:"Maximum API requests per second from single client IP (429 reply if exceeded). Connection address is used (X-Forwarded-For is ignored), so behind reverse proxy limit is shared by all clients. 0 = unlimited."`
+	WebApiRateBurst uint    `yaml:"webapi_rate_burst" yaml_comment:"Number of API requests client IP can make at once before webapi_rate_limit applies. 0 = same as webapi_rate_limit."`
+
+	TlsCertFile string `yaml:"tls_cert_file" yaml_comment:"TLS certificate file to serve HTTPS. Reloaded on SIGHUP. Empty = plain HTTP."`
+	TlsKeyFile  string `yaml:"tls_key_file" yaml_comment:"TLS private key file to serve HTTPS."`
+
+	TlsAutocertDomains  []string `yaml:"tls_autocert_domains" yaml_comment:"Domains to obtain TLS certificates for automatically from Let's Encrypt (server should be reachable at port 443). Empty = use tls_cert_file and tls_key_file."`
+	TlsAutocertCacheDir string   `yaml:"tls_autocert_cache_dir" yaml_comment:"Directory to store automatically obtained certificates in."`
 
 	ServiceName  string `yaml:"service_name" yaml_comment:"Service name for 'install' command"`
 	ServiceUser  string `yaml:"service_user" yaml_comment:"User for 'install' command"`
 	ServiceGroup string `yaml:"service_group" yaml_comment:"Group for 'install' command"`
 
+	WebserverRefuseRoot     bool `yaml:"webserver_refuse_root" yaml_comment:"Refuse to run webserver as root (warning is logged only otherwise)."`
+	WebserverDropPrivileges bool `yaml:"webserver_drop_privileges" yaml_comment:"When started as root switch to service_user and service_group right after webserver port is bound (to use ports below 1024). Files created by PreRunF are owned by root."`
+
+	ServiceWatchdogSec uint `yaml:"service_watchdog_sec" yaml_comment:"systemd watchdog timeout in seconds for 'install' command: service is restarted if it stops responding. 0 = disabled."`
+
 	InitialRootPassword string `yaml:"initial_root_password" yaml_comment:"Password to authenticate root user before users database ready. !!!DELETE THIS when you set root password in GUI."`
 
-	LogSql bool `yaml:"log_sql" yaml_comment:"Log SQL queries."`
+	DatabaseFile   string `yaml:"database_file" yaml_comment:"sqlite database file (relative to working directory or absolute path)."`
+	DatabaseDriver string `yaml:"database_driver" yaml_comment:"Database driver: sqlite, postgres or mysql (MariaDB too). Empty = sqlite."`
+	DatabaseDsn    string `yaml:"database_dsn" yaml_comment:"Database connection string (required for postgres and mysql, like 'host=localhost user=app password=secret dbname=app' for postgres or 'app:secret@tcp(localhost:3306)/app?charset=utf8mb4&parseTime=True' for mysql). Not used for sqlite."`
+
+	DatabaseMaxOpenConns           int `yaml:"database_max_open_conns" yaml_comment:"Maximum number of open database connections. 0 = unlimited (driver default)."`
+	DatabaseMaxIdleConns           int `yaml:"database_max_idle_conns" yaml_comment:"Maximum number of idle database connections in pool. 0 = driver default."`
+	DatabaseConnMaxLifetimeSeconds int `yaml:"database_conn_max_lifetime_seconds" yaml_comment:"Seconds database connection may be reused for. 0 = forever (driver default)."`
+
+	DatabaseReadOnly bool `yaml:"database_read_only" yaml_comment:"Open database in read-only mode (for replicas and read-only mounts). Schema is not migrated, write operations are rejected (raw SQL writes too for sqlite and postgres, for mysql use read-only database user)."`
+
+	MetricsEnabled bool   `yaml:"metrics_enabled" yaml_comment:"Expose Prometheus metrics (requests duration, build info) at metrics_path."`
+	MetricsPath    string `yaml:"metrics_path" yaml_comment:"Path of Prometheus metrics endpoint."`
+
+	TracingOtlpEndpoint string `yaml:"tracing_otlp_endpoint" yaml_comment:"OpenTelemetry collector OTLP/HTTP endpoint URL to export request and database query traces to (like http://localhost:4318). Empty = tracing disabled."`
+
+	ConnectivityCheckTargets []string `yaml:"connectivity_check_targets" yaml_comment:"External dependencies to test with 'check-connectivity' command: http(s) URLs (should respond with non-5xx status) or tcp://host:port addresses (SMTP server etc)."`
+
+	LogSql    bool   `yaml:"log_sql" yaml_comment:"Log SQL queries."`
+	LogLevel  string `yaml:"log_level" yaml_comment:"Minimal level of messages to log: debug, info, warn or error. Empty = info."`
+	LogFormat string `yaml:"log_format" yaml_comment:"Log output format: text or json (JSON lines with time, level and msg fields for log collectors)."`
 }
 
 func (s *AppSettingsBase) checkDefaultValues(defaults *AppSettingsBase) {
@@ -29,6 +85,31 @@ func (s *AppSettingsBase) checkDefaultValues(defaults *AppSettingsBase) {
 		s.WebserverPort = defaults.WebserverPort
 	}
 
+	//settings file is loaded later, so zero there still means "no timeout"
+	if s.WebserverReadTimeoutSec == 0 {
+		s.WebserverReadTimeoutSec = defaults.WebserverReadTimeoutSec
+	}
+
+	if s.WebserverWriteTimeoutSec == 0 {
+		s.WebserverWriteTimeoutSec = defaults.WebserverWriteTimeoutSec
+	}
+
+	if s.WebserverIdleTimeoutSec == 0 {
+		s.WebserverIdleTimeoutSec = defaults.WebserverIdleTimeoutSec
+	}
+
+	if s.MetricsPath == "" {
+		s.MetricsPath = defaults.MetricsPath
+	}
+
+	if s.DefaultPageSize <= 0 {
+		s.DefaultPageSize = defaults.DefaultPageSize
+	}
+
+	if s.MaxPageSize <= 0 {
+		s.MaxPageSize = defaults.MaxPageSize
+	}
+
 	if s.ServiceName == "" {
 		s.ServiceName = defaults.ServiceName
 	}
@@ -41,7 +122,20 @@ func (s *AppSettingsBase) checkDefaultValues(defaults *AppSettingsBase) {
 		s.ServiceGroup = defaults.ServiceGroup
 	}
 
+	if s.TlsAutocertCacheDir == "" {
+		s.TlsAutocertCacheDir = defaults.TlsAutocertCacheDir
+	}
+
+	if s.DatabaseFile == "" {
+		s.DatabaseFile = defaults.DatabaseFile
+	}
+
 	if s.InitialRootPassword == "" {
 		s.InitialRootPassword = defaults.InitialRootPassword
 	}
 }
+
+// Server uses HTTPS if certificate files or automatic certificates domains are set
+func (s *AppSettingsBase) tlsEnabled() bool {
+	return len(s.TlsAutocertDomains) > 0 || (s.TlsCertFile != "" && s.TlsKeyFile != "")
+}
@@ -0,0 +1,164 @@
+package goapp
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/mitoteam/mttools"
+)
+
+// Loads KEY=VALUE lines from .env file to process environment (before settings are loaded, so values
+// are used by SettingsEnvPrefix overrides). Variables already set in environment are not overwritten.
+// Missing file is not an error.
+//
+// Supported syntax: empty lines, "#" comments, optional "export " prefix, single quoted (literal)
+// and double quoted (with \n, \t, \", \\ escapes) values.
+func loadDotEnv(filename string) error {
+	if filename == "" || !mttools.IsFileExists(filename) {
+		return nil
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return fmt.Errorf("%s:%d: KEY=VALUE expected", filename, lineNumber)
+		}
+
+		value, err = parseDotEnvValue(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", filename, lineNumber, err)
+		}
+
+		if _, exists := os.LookupEnv(key); exists {
+			continue // real environment wins
+		}
+
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("%s:%d: %w", filename, lineNumber, err)
+		}
+	}
+
+	return scanner.Err()
+}
+
+func parseDotEnvValue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	switch value[0] {
+	case '\'':
+		end := strings.IndexByte(value[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted value")
+		}
+
+		return value[1 : end+1], nil
+
+	case '"':
+		// find closing quote skipping escaped ones
+		for i := 1; i < len(value); i++ {
+			switch value[i] {
+			case '\\':
+				i++
+			case '"':
+				return strconv.Unquote(value[:i+1])
+			}
+		}
+
+		return "", fmt.Errorf("unterminated quoted value")
+	}
+
+	// unquoted value: inline comment starts with " #"
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+
+	return value, nil
+}
+
+// Overrides settings fields with environment variables named SettingsEnvPrefix + upper-cased yaml key
+// (nested keys joined with "_"), for example MYAPP_WEBSERVER_PORT for "webserver_port" key.
+// Does nothing if SettingsEnvPrefix is empty.
+func (app *AppBase) applySettingsEnv() error {
+	if app.SettingsEnvPrefix == "" {
+		return nil
+	}
+
+	return app.applySettingsEnvStruct(reflect.ValueOf(app.AppSettings).Elem(), app.SettingsEnvPrefix)
+}
+
+func (app *AppBase) applySettingsEnvStruct(v reflect.Value, prefix string) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+
+		if !structField.IsExported() {
+			continue
+		}
+
+		if structField.Anonymous && structField.Type.Kind() == reflect.Struct {
+			if err := app.applySettingsEnvStruct(v.Field(i), prefix); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		yamlName := strings.TrimSpace(strings.Split(structField.Tag.Get("yaml"), ",")[0])
+		if yamlName == "" || yamlName == "-" {
+			continue
+		}
+
+		name := prefix + strings.ToUpper(yamlName)
+
+		if structField.Type.Kind() == reflect.Struct {
+			if err := app.applySettingsEnvStruct(v.Field(i), name+"_"); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		fieldValue := &settingsFieldValue{field: v.Field(i)}
+
+		if err := fieldValue.Set(value); err != nil {
+			return fmt.Errorf("environment variable %s: %w", name, err)
+		}
+
+		app.setSettingsOrigin(v.Field(i), "env "+name)
+	}
+
+	return nil
+}
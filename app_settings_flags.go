@@ -0,0 +1,258 @@
+package goapp
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Persistent flag bound to settings field
+type settingsFlagBinding struct {
+	settingsPath string
+	flagName     string
+	description  string
+
+	value *settingsFieldValue
+}
+
+// pflag.Value implementation setting settings struct field with reflection
+type settingsFieldValue struct {
+	field reflect.Value
+	raw   string // last value set from command line
+	isSet bool
+}
+
+// Binds persistent command line flag to settings field. settingsPath is field name or yaml key
+// (dot-separated for nested structs). Flag value overrides value loaded from settings file.
+// Supported field kinds: string, bool, integers, floats, time.Duration.
+func (app *AppBase) BindFlag(settingsPath string, flagName, description string) *AppBase {
+	app.settingsFlagBindings = append(app.settingsFlagBindings, &settingsFlagBinding{
+		settingsPath: settingsPath,
+		flagName:     flagName,
+		description:  description,
+	})
+
+	return app //for method chaining
+}
+
+// Creates persistent flags for bindings. Called from internalInit().
+func (app *AppBase) buildSettingsFlags() {
+	for _, binding := range app.settingsFlagBindings {
+		field, ok := findSettingsField(reflect.ValueOf(app.AppSettings).Elem(), binding.settingsPath)
+
+		if !ok {
+			logFatalf("BindFlag: settings field '%s' not found\n", binding.settingsPath)
+		}
+
+		binding.value = &settingsFieldValue{field: field}
+
+		// check kind is supported by setting current value
+		if err := binding.value.Set(binding.value.String()); err != nil {
+			logFatalf("BindFlag: settings field '%s': %s\n", binding.settingsPath, err.Error())
+		}
+
+		binding.value.isSet = false
+
+		flag := app.rootCmd.PersistentFlags().VarPF(binding.value, binding.flagName, "", binding.description)
+
+		if field.Kind() == reflect.Bool {
+			flag.NoOptDefVal = "true" // --flag without value means true
+		}
+	}
+}
+
+// Checks flags defined by subcommands do not shadow persistent flags of their parent commands
+// (framework's --settings and bound settings flags mostly). Called from internalInit().
+func checkFlagCollisions(cmd *cobra.Command, inherited map[string]string) error {
+	// own persistent flags are inherited by subcommands too
+	ownInherited := make(map[string]string, len(inherited))
+	for k, v := range inherited {
+		ownInherited[k] = v
+	}
+
+	var err error
+
+	check := func(flag *pflag.Flag) {
+		if err != nil {
+			return
+		}
+
+		if owner, ok := inherited["--"+flag.Name]; ok {
+			err = fmt.Errorf(
+				"flag --%s of command '%s' conflicts with persistent flag --%s of command '%s'",
+				flag.Name, cmd.CommandPath(), flag.Name, owner,
+			)
+			return
+		}
+
+		if flag.Shorthand != "" {
+			if owner, ok := inherited["-"+flag.Shorthand]; ok {
+				err = fmt.Errorf(
+					"flag -%s (--%s) of command '%s' conflicts with persistent flag shorthand -%s of command '%s'",
+					flag.Shorthand, flag.Name, cmd.CommandPath(), flag.Shorthand, owner,
+				)
+			}
+		}
+	}
+
+	cmd.Flags().VisitAll(check)
+	cmd.PersistentFlags().VisitAll(check)
+
+	if err != nil {
+		return err
+	}
+
+	cmd.PersistentFlags().VisitAll(func(flag *pflag.Flag) {
+		ownInherited["--"+flag.Name] = cmd.CommandPath()
+
+		if flag.Shorthand != "" {
+			ownInherited["-"+flag.Shorthand] = cmd.CommandPath()
+		}
+	})
+
+	for _, subCmd := range cmd.Commands() {
+		if err := checkFlagCollisions(subCmd, ownInherited); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Sets flags values to settings fields again (after settings file was loaded).
+func (app *AppBase) applySettingsFlags() error {
+	for _, binding := range app.settingsFlagBindings {
+		if binding.value != nil && binding.value.isSet {
+			if err := binding.value.Set(binding.value.raw); err != nil {
+				return fmt.Errorf("--%s: %w", binding.flagName, err)
+			}
+
+			app.setSettingsOrigin(binding.value.field, "flag --"+binding.flagName)
+		}
+	}
+
+	return nil
+}
+
+// Looks for struct field by dot-separated path of field names or yaml keys. Embedded structs are searched too.
+func findSettingsField(v reflect.Value, path string) (reflect.Value, bool) {
+	name, rest, nested := strings.Cut(path, ".")
+
+	field, ok := findSettingsStructField(v, name)
+	if !ok {
+		return reflect.Value{}, false
+	}
+
+	if nested {
+		if field.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+
+		return findSettingsField(field, rest)
+	}
+
+	return field, true
+}
+
+func findSettingsStructField(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+
+		if !structField.IsExported() {
+			continue
+		}
+
+		yamlName := strings.TrimSpace(strings.Split(structField.Tag.Get("yaml"), ",")[0])
+
+		if structField.Name == name || (yamlName != "" && yamlName == name) {
+			return v.Field(i), true
+		}
+
+		if structField.Anonymous && structField.Type.Kind() == reflect.Struct {
+			if field, ok := findSettingsStructField(v.Field(i), name); ok {
+				return field, true
+			}
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+func (v *settingsFieldValue) String() string {
+	if !v.field.IsValid() {
+		return ""
+	}
+
+	return fmt.Sprintf("%v", v.field.Interface())
+}
+
+func (v *settingsFieldValue) Set(s string) error {
+	switch v.field.Kind() {
+	case reflect.String:
+		v.field.SetString(s)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+
+		v.field.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.field.Type() == reflect.TypeFor[time.Duration]() {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+
+			v.field.SetInt(int64(d))
+		} else {
+			i, err := strconv.ParseInt(s, 10, v.field.Type().Bits())
+			if err != nil {
+				return err
+			}
+
+			v.field.SetInt(i)
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, v.field.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		v.field.SetUint(u)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.field.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		v.field.SetFloat(f)
+
+	default:
+		return fmt.Errorf("unsupported field type %s", v.field.Type().String())
+	}
+
+	v.raw = s
+	v.isSet = true
+
+	return nil
+}
+
+func (v *settingsFieldValue) Type() string {
+	if v.field.Type() == reflect.TypeFor[time.Duration]() {
+		return "duration"
+	}
+
+	return v.field.Kind().String()
+}
@@ -0,0 +1,114 @@
+package goapp
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// settings values origins (used if no other origin recorded)
+const (
+	SettingsOriginDefault    = "default"
+	SettingsOriginDevDefault = "development default"
+	SettingsOriginDemo       = "demo mode"
+)
+
+// Settings field identity: address is not enough, nested struct and its first field share it.
+type settingsFieldKey struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+func newSettingsFieldKey(field reflect.Value) settingsFieldKey {
+	return settingsFieldKey{addr: field.Addr().Pointer(), typ: field.Type()}
+}
+
+// Records source settings field value was taken from. Sources applied later override earlier ones.
+func (app *AppBase) setSettingsOrigin(field reflect.Value, origin string) {
+	if app.settingsOrigins == nil {
+		app.settingsOrigins = make(map[settingsFieldKey]string)
+	}
+
+	app.settingsOrigins[newSettingsFieldKey(field)] = origin
+}
+
+// Same as setSettingsOrigin() for settings field path (field names or yaml keys, dot-separated).
+func (app *AppBase) setSettingsPathOrigin(settingsPath string, origin string) {
+	if field, ok := findSettingsField(reflect.ValueOf(app.AppSettings).Elem(), settingsPath); ok {
+		app.setSettingsOrigin(field, origin)
+	}
+}
+
+// Returns source effective value of settings field was taken from: settings file, command line flag, default etc.
+// settingsPath is field name or yaml key (dot-separated for nested structs).
+func (app *AppBase) SettingsOrigin(settingsPath string) (string, error) {
+	field, ok := findSettingsField(reflect.ValueOf(app.AppSettings).Elem(), settingsPath)
+	if !ok {
+		return "", fmt.Errorf("settings field '%s' not found", settingsPath)
+	}
+
+	if origin, ok := app.settingsOrigins[newSettingsFieldKey(field)]; ok {
+		return origin, nil
+	}
+
+	return SettingsOriginDefault, nil
+}
+
+// Records origin of all keys present in settings file.
+func (app *AppBase) setSettingsFileOrigins(filename, origin string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	var root yaml.Node
+
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return err
+	}
+
+	if len(root.Content) > 0 {
+		app.setYamlNodeOrigins(root.Content[0], "", origin)
+	}
+
+	return nil
+}
+
+func (app *AppBase) setYamlNodeOrigins(node *yaml.Node, prefix string, origin string) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	// mapping node content: key1, value1, key2, value2...
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		path := prefix + node.Content[i].Value
+
+		app.setSettingsPathOrigin(path, origin)
+		app.setYamlNodeOrigins(node.Content[i+1], path+".", origin)
+	}
+}
+
+// Prints settings field effective value and its origin. Sensitive values are masked.
+func (app *AppBase) printSettingsOrigin(settingsPath string) error {
+	origin, err := app.SettingsOrigin(settingsPath)
+	if err != nil {
+		return err
+	}
+
+	field, _ := findSettingsField(reflect.ValueOf(app.AppSettings).Elem(), settingsPath)
+	value := fmt.Sprintf("%v", field.Interface())
+
+	lowerPath := strings.ToLower(settingsPath)
+	for _, sensitive := range []string{"secret", "password", "dsn", "token"} {
+		if strings.Contains(lowerPath, sensitive) && value != "" {
+			value = capturedRedactedValue
+		}
+	}
+
+	fmt.Printf("%s = %s\norigin: %s\n", settingsPath, value, origin)
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package goapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Returned (wrapped) when remote settings were not fetched in SettingsFetchTimeout.
+// Check it with errors.Is() to report hung settings endpoint.
+var ErrSettingsFetchTimeout = errors.New("settings fetch timed out")
+
+// Settings "filename" is http(s) URL to fetch settings from
+func isRemoteSettings(filename string) bool {
+	lower := strings.ToLower(filename)
+
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// Downloads remote settings to temporary file to be loaded same way as local one. Fetch is cancelled when
+// BaseContext is cancelled or SettingsFetchTimeout is exceeded. Caller should remove returned file.
+func (app *AppBase) fetchRemoteSettings(url string) (string, error) {
+	ctx := app.BaseContext
+
+	if app.SettingsFetchTimeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, app.SettingsFetchTimeout)
+		defer cancel()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return "", fmt.Errorf("%w: %s (timeout %s)", ErrSettingsFetchTimeout, url, app.SettingsFetchTimeout)
+		}
+
+		return "", fmt.Errorf("unable to fetch settings from %s: %w", url, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unable to fetch settings from %s: %s", url, response.Status)
+	}
+
+	file, err := os.CreateTemp("", "goapp-settings-*.yml")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	// one extra byte is enough for size limit check to fail
+	_, err = io.Copy(file, io.LimitReader(response.Body, app.SettingsMaxFileSize+1))
+
+	if err != nil {
+		os.Remove(file.Name())
+
+		if errors.Is(err, context.DeadlineExceeded) {
+			return "", fmt.Errorf("%w: %s (timeout %s)", ErrSettingsFetchTimeout, url, app.SettingsFetchTimeout)
+		}
+
+		return "", fmt.Errorf("unable to fetch settings from %s: %w", url, err)
+	}
+
+	return file.Name(), nil
+}
@@ -0,0 +1,101 @@
+package goapp
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Saves settings to YAML file. Every key gets its field documentation as a comment above it.
+// Documentation is taken from `doc` struct tag, `yaml_comment` tag is used if there is no `doc` one.
+func saveYamlSettingsWithDocs(path string, comment string, settings any) error {
+	node := &yaml.Node{}
+
+	if err := node.Encode(settings); err != nil {
+		return err
+	}
+
+	node.HeadComment = strings.ReplaceAll(comment, "\n", "\n# ") +
+		"\n# Saved on: " + time.Now().Format(time.RFC3339) +
+		"\n#\n\n"
+
+	addYamlSettingsDocs(node, reflect.TypeOf(settings))
+
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// Sets mapping keys comments from struct fields docs (recursively for nested structs).
+func addYamlSettingsDocs(node *yaml.Node, t reflect.Type) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if node.Kind != yaml.MappingNode || t.Kind() != reflect.Struct {
+		return
+	}
+
+	// mapping node content: key1, value1, key2, value2...
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+
+		field, ok := findYamlSettingsField(t, keyNode.Value)
+		if !ok {
+			continue
+		}
+
+		doc := field.Tag.Get("doc")
+		if doc == "" {
+			doc = field.Tag.Get("yaml_comment")
+		}
+
+		if doc != "" {
+			keyNode.HeadComment = strings.ReplaceAll(doc, "\n", "\n# ")
+		}
+
+		addYamlSettingsDocs(valueNode, field.Type)
+	}
+}
+
+// Looks for struct field serialized with yaml key (inlined structs are searched too).
+func findYamlSettingsField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		name, flags, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		name = strings.TrimSpace(name)
+
+		if name == "-" {
+			continue
+		}
+
+		if strings.Contains(flags, "inline") {
+			if inlineField, ok := findYamlSettingsField(field.Type, key); ok {
+				return inlineField, true
+			}
+
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		if name == key {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
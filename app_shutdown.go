@@ -0,0 +1,108 @@
+package goapp
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Runs fn in background goroutine. ctx is cancelled when app is being shutdown
+// and graceful shutdown waits for fn to return (up to ShutdownTimeout).
+func (app *AppBase) Go(fn func(ctx context.Context)) {
+	app.backgroundWg.Add(1)
+
+	go func() {
+		defer app.backgroundWg.Done()
+		fn(app.BaseContext)
+	}()
+}
+
+// Requests application shutdown. Running webserver ('run' command) is stopped gracefully the same way
+// as on SIGINT, BaseContext is cancelled right away otherwise. Safe to call multiple times and from
+// request handlers (shutdown waits for active requests to finish).
+func (app *AppBase) Shutdown() {
+	app.shutdownOnce.Do(func() {
+		close(app.shutdownRequested)
+
+		if !app.serving.Load() {
+			app.appShutdownF()
+		}
+	})
+}
+
+// Stops application in following order, whole procedure is limited by ShutdownTimeout:
+//  1. web server stops accepting new connections and waits for active requests to finish.
+//     Requests still running at the middle of ShutdownTimeout (streaming ones usually) get their
+//     contexts cancelled, so they are able to finish cleanly before hard deadline.
+//  2. BaseContext is cancelled, so background goroutines (Go(), SubscribeAsync()) are notified
+//  3. waiting for background goroutines to finish
+//  4. PostRunF is called
+//  5. database is closed
+//
+// Workers are cancelled after web server is drained, so active requests still can use them.
+// Database is closed last, so both requests and workers are able to use it until finished.
+func (app *AppBase) gracefulShutdown(httpSrv *http.Server) error {
+	// not derived from BaseContext, it is cancelled during shutdown
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), app.ShutdownTimeout)
+	defer cancel()
+
+	if err := systemdNotify("STOPPING=1"); err != nil {
+		logErrorf("systemd notify error: %s", err)
+	}
+
+	// readiness probe fails while server is drained, so no new traffic is routed here
+	app.ready.Store(false)
+
+	logInfof("Shutting down web server")
+	app.shutdownProgress(ShutdownStageDraining)
+
+	stopRequestsTimer := time.AfterFunc(app.ShutdownTimeout/2, app.cancelRequestsF)
+	defer stopRequestsTimer.Stop()
+
+	if err := httpSrv.Shutdown(shutdownCtx); err != nil {
+		logWarnf("Web server forced to shutdown: %s", err)
+	}
+
+	// Notify application we are shutting down (via context.WithCancel())
+	app.appShutdownF()
+
+	app.shutdownProgress(ShutdownStageWaitingGoroutines)
+
+	goroutinesDone := make(chan struct{})
+	go func() {
+		app.backgroundWg.Wait()
+		close(goroutinesDone)
+	}()
+
+	select {
+	case <-goroutinesDone:
+	case <-shutdownCtx.Done():
+		logWarnf("Shutdown timeout exceeded, background goroutines are still running")
+	}
+
+	var err error
+
+	if app.PostRunF != nil {
+		err = app.PostRunF()
+	}
+
+	//close database if app left it opened
+	if DbSchema.Db() != nil {
+		app.shutdownProgress(ShutdownStageClosingDb)
+
+		if closeErr := DbSchema.Close(); closeErr != nil {
+			logErrorf("%s", closeErr)
+
+			if err == nil {
+				err = closeErr
+			}
+		}
+	}
+
+	app.shutdownTracing(shutdownCtx)
+
+	logInfof("Shutdown complete")
+	app.shutdownProgress(ShutdownStageDone)
+
+	return err
+}
@@ -0,0 +1,266 @@
+package goapp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testShutdownSettings struct {
+	AppSettingsBase `yaml:",inline"`
+}
+
+func TestGracefulShutdownOrder(t *testing.T) {
+	t.Chdir(t.TempDir()) // database file is created in working directory
+
+	app := NewAppBase(&testShutdownSettings{})
+
+	var mu sync.Mutex
+	var events []string
+
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		events = append(events, event)
+	}
+
+	app.OnShutdownProgressF = func(stage string) {
+		if stage == ShutdownStageClosingDb {
+			record(stage)
+		}
+	}
+
+	app.PostRunF = func() error {
+		record("post-run")
+		return nil
+	}
+
+	if err := DbSchema.connect(false); err != nil {
+		t.Fatal(err)
+	}
+
+	app.Go(func(ctx context.Context) {
+		<-ctx.Done()
+		time.Sleep(50 * time.Millisecond)
+		record("worker-done")
+	})
+
+	requestStarted := make(chan struct{})
+
+	httpSrv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(requestStarted)
+			time.Sleep(100 * time.Millisecond)
+			record("request-done")
+		}),
+		BaseContext: func(l net.Listener) context.Context { return app.requestsContext },
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go httpSrv.Serve(listener)
+
+	go http.Get("http://" + listener.Addr().String())
+	<-requestStarted
+
+	if err := app.gracefulShutdown(httpSrv); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"request-done", "worker-done", "post-run", ShutdownStageClosingDb}
+
+	if len(events) != len(expected) {
+		t.Fatalf("expected shutdown events %v, got %v", expected, events)
+	}
+
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Fatalf("expected shutdown events %v, got %v", expected, events)
+		}
+	}
+
+	if DbSchema.Db() != nil {
+		t.Error("database was not closed")
+	}
+}
+
+func TestGracefulShutdownTimeout(t *testing.T) {
+	app := NewAppBase(&testShutdownSettings{})
+	app.ShutdownTimeout = 50 * time.Millisecond
+
+	release := make(chan struct{})
+	defer close(release)
+
+	app.Go(func(ctx context.Context) {
+		<-release // ignores ctx cancellation
+	})
+
+	start := time.Now()
+
+	if err := app.gracefulShutdown(&http.Server{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("shutdown was not bounded by ShutdownTimeout (took %s)", elapsed)
+	}
+}
+
+func TestGracefulShutdownStreaming(t *testing.T) {
+	app := NewAppBase(&testShutdownSettings{})
+	app.ShutdownTimeout = 400 * time.Millisecond
+
+	streamStarted := make(chan struct{})
+	handlerDone := make(chan struct{})
+
+	httpSrv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer close(handlerDone)
+
+			flusher := w.(http.Flusher)
+			ticker := time.NewTicker(10 * time.Millisecond)
+			defer ticker.Stop()
+
+			for i := 0; ; i++ {
+				select {
+				case <-r.Context().Done():
+					io.WriteString(w, "data: bye\n\n")
+					return
+
+				case <-ticker.C:
+					io.WriteString(w, "data: tick\n\n")
+					flusher.Flush()
+
+					if i == 0 {
+						close(streamStarted)
+					}
+				}
+			}
+		}),
+		BaseContext: func(l net.Listener) context.Context { return app.requestsContext },
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go httpSrv.Serve(listener)
+
+	type streamResult struct {
+		body []byte
+		err  error
+	}
+
+	result := make(chan streamResult, 1)
+
+	go func() {
+		response, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			result <- streamResult{err: err}
+			return
+		}
+		defer response.Body.Close()
+
+		body, err := io.ReadAll(response.Body)
+		result <- streamResult{body: body, err: err}
+	}()
+
+	<-streamStarted
+
+	start := time.Now()
+
+	if err := app.gracefulShutdown(httpSrv); err != nil {
+		t.Fatal(err)
+	}
+
+	if elapsed := time.Since(start); elapsed >= app.ShutdownTimeout {
+		t.Errorf("streaming request was not finished before shutdown deadline (took %s)", elapsed)
+	}
+
+	select {
+	case <-handlerDone:
+	default:
+		t.Error("streaming handler is still running after shutdown")
+	}
+
+	stream := <-result
+
+	if stream.err != nil {
+		t.Fatalf("stream was cut: %s", stream.err)
+	}
+
+	if !strings.HasSuffix(string(stream.body), "data: bye\n\n") {
+		t.Errorf("stream was not finished cleanly: %q", stream.body)
+	}
+}
+
+func TestPreRunFailureCancelsBaseContext(t *testing.T) {
+	app := NewAppBase(&testShutdownSettings{})
+
+	cancelled := make(chan struct{})
+
+	app.PreRunF = func() error {
+		go func() {
+			<-app.BaseContext.Done()
+			close(cancelled)
+		}()
+
+		return errors.New("pre-run failed")
+	}
+
+	cmd := app.buildRunCmd()
+
+	if err := cmd.PreRunE(cmd, nil); err == nil {
+		t.Fatal("expected PreRunE error")
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("BaseContext was not cancelled on PreRunF failure")
+	}
+}
+
+func TestShutdownTwice(t *testing.T) {
+	app := NewAppBase(&testShutdownSettings{})
+
+	app.Shutdown()
+	app.Shutdown() // should not panic on closed channel
+
+	select {
+	case <-app.BaseContext.Done():
+	default:
+		t.Error("BaseContext was not cancelled by Shutdown()")
+	}
+}
+
+func TestPreRunTimeout(t *testing.T) {
+	app := NewAppBase(&testShutdownSettings{})
+	app.PreRunTimeout = 50 * time.Millisecond
+
+	app.PreRunF = func() error {
+		<-app.BaseContext.Done() // hung startup hook
+		return nil
+	}
+
+	cmd := app.buildRunCmd()
+
+	if err := cmd.PreRunE(cmd, nil); !errors.Is(err, ErrPreRunTimeout) {
+		t.Fatalf("expected ErrPreRunTimeout, got %v", err)
+	}
+
+	if app.BaseContext.Err() == nil {
+		t.Error("BaseContext was not cancelled on PreRunF timeout")
+	}
+}
@@ -0,0 +1,84 @@
+package goapp
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// Returned (wrapped) when PreRunF did not finish in PreRunTimeout. Check it with errors.Is().
+var ErrPreRunTimeout = errors.New("PreRunF timed out")
+
+// PreRunF still running is reported every preRunProgressInterval
+const preRunProgressInterval = 10 * time.Second
+
+// Calls PreRunF logging its duration (and progress if it is slow). Without PreRunTimeout PreRunF is called
+// right in caller's goroutine. Startup is aborted with ErrPreRunTimeout if PreRunTimeout is set and exceeded.
+// BaseContext is cancelled on error, so PreRunF code watching it can stop, hung one is left behind as
+// process is going to exit anyway.
+func (app *AppBase) runPreRunF() error {
+	if app.PreRunF == nil {
+		return nil
+	}
+
+	start := time.Now()
+
+	progress := time.NewTicker(preRunProgressInterval)
+	defer progress.Stop()
+
+	if app.PreRunTimeout <= 0 {
+		stopProgress := make(chan struct{})
+		defer close(stopProgress)
+
+		go func() {
+			for {
+				select {
+				case <-stopProgress:
+					return
+
+				case <-progress.C:
+					logPreRunProgress(start)
+				}
+			}
+		}()
+
+		err := app.PreRunF()
+		logInfof("PreRunF finished in %s", time.Since(start).Round(time.Millisecond))
+
+		return err
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("PreRunF panicked: %v\n%s", r, debug.Stack())
+			}
+		}()
+
+		done <- app.PreRunF()
+	}()
+
+	timer := time.NewTimer(app.PreRunTimeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case err := <-done:
+			logInfof("PreRunF finished in %s", time.Since(start).Round(time.Millisecond))
+			return err
+
+		case <-progress.C:
+			logPreRunProgress(start)
+
+		case <-timer.C:
+			return fmt.Errorf("%w after %s, startup aborted", ErrPreRunTimeout, app.PreRunTimeout)
+		}
+	}
+}
+
+func logPreRunProgress(start time.Time) {
+	logWarnf("PreRunF is still running (%s), web server is not started yet", time.Since(start).Round(time.Second))
+}
@@ -0,0 +1,171 @@
+package goapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	gorm "gorm.io/gorm"
+)
+
+// instrumentation name for tracer
+const tracerName = "github.com/mitoteam/goapp"
+
+// gorm statement instance key to keep query span in
+const gormSpanKey = "goapp:span"
+
+// Sets up global OpenTelemetry tracer provider exporting spans to tracing_otlp_endpoint.
+// Does nothing if endpoint is not set. Spans are flushed by graceful shutdown.
+func (app *AppBase) setupTracing() error {
+	endpoint := app.baseSettings.TracingOtlpEndpoint
+
+	if endpoint == "" {
+		return nil
+	}
+
+	exporter, err := otlptracehttp.New(app.BaseContext, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return fmt.Errorf("unable to create OTLP exporter: %w", err)
+	}
+
+	serviceName := app.baseSettings.ServiceName
+	if serviceName == "" {
+		serviceName = app.ExecutableName
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", serviceName),
+			attribute.String("service.version", app.Version),
+		)),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	app.tracingShutdownF = provider.Shutdown
+
+	logInfof("OpenTelemetry tracing enabled: %s", endpoint)
+
+	return nil
+}
+
+// Flushes and stops tracing (if enabled).
+func (app *AppBase) shutdownTracing(ctx context.Context) {
+	if app.tracingShutdownF == nil {
+		return
+	}
+
+	if err := app.tracingShutdownF(ctx); err != nil {
+		logErrorf("Tracing shutdown error: %s", err)
+	}
+
+	app.tracingShutdownF = nil
+}
+
+// Middleware starting span for every request. Incoming trace context (traceparent header) is continued.
+// Request context carries the span, so DbSchema.Db().WithContext(c.Request.Context()) queries become its children.
+func (app *AppBase) tracingMiddleware(c *gin.Context) {
+	ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx, c.Request.Method+" "+route,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("http.request.method", c.Request.Method),
+			attribute.String("http.route", route),
+			attribute.String("url.path", c.Request.URL.Path),
+			attribute.String("client.address", c.ClientIP()),
+		),
+	)
+	defer span.End()
+
+	c.Request = c.Request.WithContext(ctx)
+
+	c.Next()
+
+	status := c.Writer.Status()
+	span.SetAttributes(attribute.Int("http.response.status_code", status))
+
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+}
+
+// Registers gorm callbacks creating span for every query (child of span from statement context).
+func registerTracingCallbacks(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("goapp:trace_before", tracingBeforeCallback("create")),
+		callbacks.Create().After("gorm:create").Register("goapp:trace_after", tracingAfterCallback),
+		callbacks.Query().Before("gorm:query").Register("goapp:trace_before", tracingBeforeCallback("query")),
+		callbacks.Query().After("gorm:query").Register("goapp:trace_after", tracingAfterCallback),
+		callbacks.Update().Before("gorm:update").Register("goapp:trace_before", tracingBeforeCallback("update")),
+		callbacks.Update().After("gorm:update").Register("goapp:trace_after", tracingAfterCallback),
+		callbacks.Delete().Before("gorm:delete").Register("goapp:trace_before", tracingBeforeCallback("delete")),
+		callbacks.Delete().After("gorm:delete").Register("goapp:trace_after", tracingAfterCallback),
+		callbacks.Row().Before("gorm:row").Register("goapp:trace_before", tracingBeforeCallback("row")),
+		callbacks.Row().After("gorm:row").Register("goapp:trace_after", tracingAfterCallback),
+		callbacks.Raw().Before("gorm:raw").Register("goapp:trace_before", tracingBeforeCallback("raw")),
+		callbacks.Raw().After("gorm:raw").Register("goapp:trace_after", tracingAfterCallback),
+	)
+}
+
+func tracingBeforeCallback(operation string) func(tx *gorm.DB) {
+	return func(tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		_, span := otel.Tracer(tracerName).Start(ctx, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system.name", tx.Dialector.Name()),
+				attribute.String("db.operation.name", operation),
+			),
+		)
+
+		tx.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func tracingAfterCallback(tx *gorm.DB) {
+	value, ok := tx.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("db.query.text", tx.Statement.SQL.String()),
+		attribute.String("db.collection.name", tx.Statement.Table),
+		attribute.Int64("db.response.returned_rows", tx.Statement.RowsAffected),
+	)
+
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(tx.Error)
+		span.SetStatus(codes.Error, tx.Error.Error())
+	}
+}
@@ -2,15 +2,22 @@ package goapp
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"maps"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"reflect"
+	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"github.com/mitoteam/mttools"
@@ -30,22 +37,36 @@ func (app *AppBase) buildRootCmd() {
 		},
 
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			app.currentCmd = cmd
+			app.currentArgs = args
+
+			//filename could be changed with --settings flag
+			if err := app.checkSettingsFilename(); err != nil {
+				return err
+			}
+
 			//Load Settings
-			if mttools.IsFileExists(app.AppSettingsFilename) {
+			if app.demoMode {
+				app.applyDemoSettings()
+			} else if isRemoteSettings(app.AppSettingsFilename) || mttools.IsFileExists(app.AppSettingsFilename) {
 				if err := app.loadSettings(); err != nil {
 					return err
 				}
 			} else {
 				//do not require settings loading just for certain commands
-				no_settings_required_cmd_list := []string{"init", "version", "info", "help"}
+				no_settings_required_cmd_list := []string{"init", "version", "info", "help", "gen-unit"}
 
 				if !mttools.InSlice(cmd.Name(), no_settings_required_cmd_list) {
-					log.Fatalf(
-						"No "+app.AppSettingsFilename+" file found. Please create one or use `%s init` command.\n", app.ExecutableName,
+					logFatalf(
+						"No %s file found. Please create one or use `%s init` command.", app.AppSettingsFilename, app.ExecutableName,
 					)
 				}
 			}
 
+			if err := app.setupLogging(); err != nil {
+				return err
+			}
+
 			if app.PreCmdF != nil {
 				if err := app.PreCmdF(cmd); err != nil {
 					return err
@@ -68,14 +89,40 @@ func (app *AppBase) buildRootCmd() {
 }
 
 func (app *AppBase) buildVersionCmd() *cobra.Command {
-	return &cobra.Command{
+	var verbose bool
+
+	cmd := &cobra.Command{
 		Use:   "version",
 		Short: "Prints the raw version number of " + app.AppName + ".",
 
 		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Println(app.Version)
+			if !verbose {
+				fmt.Println(app.Version)
+				return
+			}
+
+			fmt.Printf("%s: %s\n", app.AppName, app.Version)
+			fmt.Printf("Commit: %s\n", app.BuildCommit)
+			fmt.Printf("Built: at %s with %s\n", app.BuildTime, app.BuildWith)
+			app.printVersionComponents()
 		},
 	}
+
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Print build information and components versions too.")
+
+	return cmd
+}
+
+// Prints additional components versions (if any were registered)
+func (app *AppBase) printVersionComponents() {
+	if len(app.versionComponents) == 0 {
+		return
+	}
+
+	fmt.Print("Components:\n")
+	for _, component := range app.versionComponents {
+		fmt.Printf("  %s: %s\n", component.name, component.version)
+	}
 }
 
 func (app *AppBase) buildLicenseCmd() *cobra.Command {
@@ -99,18 +146,16 @@ func (app *AppBase) buildInstallCmd() *cobra.Command {
 
 		Run: func(cmd *cobra.Command, args []string) {
 			if mttools.IsSystemdAvailable() {
-				unitData := &mttools.ServiceData{
-					Name:      app.baseSettings.ServiceName,
-					User:      app.baseSettings.ServiceUser,
-					Group:     app.baseSettings.ServiceGroup,
-					Autostart: app.serviceAutostart,
+				unitData, err := app.systemdServiceData()
+				if err != nil {
+					logFatalf("%s", err)
 				}
 
-				if err := unitData.InstallSystemdService(); err != nil {
-					log.Fatal(err)
+				if err := installSystemdService(unitData); err != nil {
+					logFatalf("%s", err)
 				}
 			} else {
-				log.Fatalf(
+				logFatalf(
 					"Directory %s does not exists. Only systemd based services supported for now.\n",
 					mttools.SystemdServiceDirPath,
 				)
@@ -140,10 +185,10 @@ func (app *AppBase) buildUninstallCmd() *cobra.Command {
 				}
 
 				if err := unitData.UninstallSystemdService(); err != nil {
-					log.Fatal(err)
+					logFatalf("%s", err)
 				}
 			} else {
-				log.Fatalf(
+				logFatalf(
 					"Directory %s does not exists. Only systemd based services supported for now.\n",
 					mttools.SystemdServiceDirPath,
 				)
@@ -154,6 +199,24 @@ func (app *AppBase) buildUninstallCmd() *cobra.Command {
 	return cmd
 }
 
+func (app *AppBase) buildGenUnitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gen-unit",
+		Short: "Prints systemd service unit for " + app.AppName + " (same as 'install' creates) to stdout.",
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			unitData, err := app.systemdServiceData()
+			if err != nil {
+				return err
+			}
+
+			return renderSystemdUnit(os.Stdout, unitData)
+		},
+	}
+
+	return cmd
+}
+
 func (app *AppBase) buildInitCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "init",
@@ -189,16 +252,24 @@ want to change and remove all others with default values to keep this as simple
 }
 
 func (app *AppBase) buildInfoCmd() *cobra.Command {
+	var jsonOutput bool
+
 	cmd := &cobra.Command{
 		Use:   "info",
 		Short: "Prints info about app, settings, status etc.",
 
 		Run: func(cmd *cobra.Command, args []string) {
+			if jsonOutput {
+				app.printInfoJson()
+				return
+			}
+
 			fmt.Printf("%s\n", app.AppName)
 			fmt.Print("================================\n")
 			fmt.Printf("Version: %s\n", app.Version)
 			fmt.Printf("Commit: %s\n", app.BuildCommit)
 			fmt.Printf("Built: at %s with %s\n", app.BuildTime, app.BuildWith)
+			app.printVersionComponents()
 
 			// Settings
 			fmt.Print("\n================================\n")
@@ -206,6 +277,10 @@ func (app *AppBase) buildInfoCmd() *cobra.Command {
 			fmt.Print("================================\n")
 			if app.baseSettings.LoadedFromFile {
 				app.printSettings()
+
+				for _, message := range app.settingsWarnings {
+					fmt.Println("WARNING: " + message)
+				}
 			} else {
 				fmt.Printf("File %s not found.\n", app.AppSettingsFilename)
 			}
@@ -216,85 +291,162 @@ func (app *AppBase) buildInfoCmd() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print app info and runtime stats as JSON.")
+
 	return cmd
 }
 
+func (app *AppBase) printInfoJson() {
+	components := make(map[string]string)
+	for _, component := range app.versionComponents {
+		components[component.name] = component.version
+	}
+
+	info := map[string]any{
+		"name":       app.AppName,
+		"version":    app.Version,
+		"commit":     app.BuildCommit,
+		"build_time": app.BuildTime,
+		"build_with": app.BuildWith,
+		"components": components,
+		"warnings":   app.settingsWarnings,
+		"runtime":    app.RuntimeStats(),
+	}
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		logFatalf("%s", err)
+	}
+
+	fmt.Println(string(data))
+}
+
 func (app *AppBase) buildRunCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "run",
 		Short: "Runs webserver.",
 
 		RunE: func(cmd *cobra.Command, args []string) error {
+			// database opened in PreRunF is closed by graceful shutdown, this one is for startup errors
+			defer DbSchema.Close()
+
 			address := app.baseSettings.WebserverHostname +
 				":" + strconv.FormatUint(uint64(app.baseSettings.WebserverPort), 10)
 
 			//Graceful shutdown according to https://github.com/gorilla/mux#graceful-shutdown
 			httpSrv := &http.Server{
-				Addr:         address,
-				WriteTimeout: time.Second * 10,
-				ReadTimeout:  time.Second * 20,
-				IdleTimeout:  time.Second * 60,
-				Handler:      app.Handler(),
-				BaseContext:  func(l net.Listener) context.Context { return app.BaseContext },
+				Addr:           address,
+				WriteTimeout:   webserverTimeout(app.baseSettings.WebserverWriteTimeoutSec),
+				ReadTimeout:    webserverTimeout(app.baseSettings.WebserverReadTimeoutSec),
+				IdleTimeout:    webserverTimeout(app.baseSettings.WebserverIdleTimeoutSec),
+				MaxHeaderBytes: app.baseSettings.WebserverMaxHeaderBytes,
+				Handler:        app.Handler(),
+				BaseContext:    func(l net.Listener) context.Context { return app.requestsContext },
 			}
 
-			log.Printf("Starting up web server at http://%s\nPress Ctrl + C to stop it.\n", address)
+			if app.baseSettings.WebserverDisableKeepAlives {
+				httpSrv.SetKeepAlivesEnabled(false)
+				logInfof("HTTP keep-alives disabled")
+			}
 
-			go func() {
-				if err := httpSrv.ListenAndServe(); err != nil {
-					log.Println(err)
-				}
-			}()
+			if err := app.configureServerTls(httpSrv); err != nil {
+				return err
+			}
 
-			cancel_channel := make(chan os.Signal, 1)
+			// last chance for app to adjust server
+			if app.ConfigureHttpServerF != nil {
+				app.ConfigureHttpServerF(httpSrv)
+			}
 
-			// We'll accept graceful shutdowns when quit via SIGINT (Ctrl+C)
-			// SIGKILL, SIGQUIT or SIGTERM (Ctrl+/) will not be caught.
-			signal.Notify(cancel_channel, os.Interrupt, os.Kill)
+			// TLS could be configured by app as well
+			useTls := httpSrv.TLSConfig != nil
 
-			// Block execution until we receive our signal.
-			<-cancel_channel
+			listener, err := net.Listen("tcp", address)
+			if err != nil {
+				return err
+			}
 
-			// Notify application we are shutting down (via context.WithCancel())
-			app.appShutdownF()
+			// port is bound already
+			if err := app.dropRootPrivileges(); err != nil {
+				listener.Close()
+				return err
+			}
 
-			log.Println("Shutting down web server")
+			if app.demoMode {
+				app.demoListening(listener)
+			}
+
+			// real address (port could be chosen by system)
+			address = listener.Addr().String()
 
-			// Create a deadline to wait for (10s).
-			shutdownCtx, cancel := context.WithTimeout(app.BaseContext, app.ShutdownTimeout)
-			defer cancel()
+			if useTls {
+				logInfof("Starting up web server at https://%s\nPress Ctrl + C to stop it.", address)
+
+				go func() {
+					//certificate is provided by TLSConfig.GetCertificate
+					if err := httpSrv.ServeTLS(listener, "", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+						logErrorf("Web server ERROR: %s", err)
+					}
+				}()
+			} else {
+				logInfof("Starting up web server at http://%s\nPress Ctrl + C to stop it.", address)
 
-			if err := httpSrv.Shutdown(shutdownCtx); err != nil {
-				log.Fatal("Server forced to shutdown:", err)
+				go func() {
+					if err := httpSrv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+						logErrorf("Web server ERROR: %s", err)
+					}
+				}()
 			}
 
-			return nil
-		},
+			// listening already, tell systemd service is started
+			if err := systemdNotify("READY=1"); err != nil {
+				logErrorf("systemd notify error: %s", err)
+			}
 
-		// Do startup procedures
-		PreRunE: func(cmd *cobra.Command, args []string) error {
-			log.Printf("%s version: %s\n", app.AppName, app.Version)
+			app.startSystemdWatchdog()
 
-			var err error
+			cancel_channel := make(chan os.Signal, 1)
+
+			// We'll accept graceful shutdowns when quit via SIGINT (Ctrl+C) or SIGTERM (`systemctl stop`).
+			// SIGKILL can not be caught, SIGQUIT (Ctrl+/) is left to Go runtime (dumps goroutines).
+			signal.Notify(cancel_channel, os.Interrupt, syscall.SIGTERM)
 
-			if app.PreRunF != nil {
-				err = app.PreRunF()
+			app.serving.Store(true)
+			app.ready.Store(true) // PreRunF is finished already
+
+			// Block execution until we receive our signal or Shutdown() is called.
+			select {
+			case <-cancel_channel:
+			case <-app.shutdownRequested:
+				logInfof("Shutdown requested")
 			}
 
-			return err
+			// Do shutdown procedures
+			return app.gracefulShutdown(httpSrv)
 		},
 
-		// Do shutdown procedures
-		PostRunE: func(cmd *cobra.Command, args []string) error {
-			var err error
+		// Do startup procedures
+		PreRunE: func(cmd *cobra.Command, args []string) (err error) {
+			// server is not going to start on error, let goroutines started in PreRunF finish
+			defer func() {
+				if err != nil {
+					app.appShutdownF()
+				}
+			}()
+
+			logInfof("%s version: %s", app.AppName, app.Version)
+			app.logSettingsWarnings()
 
-			if app.PostRunF != nil {
-				err = app.PostRunF()
+			if err = app.checkRunAsRoot(); err != nil {
+				return err
 			}
 
-			log.Println("Shutdown complete")
+			// before PreRunF to have database queries traced too
+			if err = app.setupTracing(); err != nil {
+				return err
+			}
 
-			return err
+			return app.runPreRunF()
 		},
 	}
 
@@ -303,7 +455,15 @@ func (app *AppBase) buildRunCmd() *cobra.Command {
 		&app.WebRouterLogRequests,
 		"log-requests",
 		false,
-		"Extended web router queries logging.",
+		"Log every web request (method, path, status, duration, client IP).",
+	)
+
+	// skip schema migration
+	cmd.PersistentFlags().BoolVar(
+		&DbSchema.noMigrate,
+		"no-migrate",
+		false,
+		"Do not migrate database schema on startup (schema should be migrated separately).",
 	)
 
 	// log SQL queries
@@ -316,3 +476,284 @@ func (app *AppBase) buildRunCmd() *cobra.Command {
 
 	return cmd
 }
+
+func (app *AppBase) buildReplayCmd() *cobra.Command {
+	var target string
+
+	cmd := &cobra.Command{
+		Use:   "replay <file>",
+		Short: "Re-issues requests recorded to capture file against target instance.",
+		Args:  cobra.ExactArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if target == "" {
+				target = app.baseSettings.BaseUrl
+			}
+
+			log.Printf("Replaying requests from %s against %s\n", args[0], target)
+
+			return replayCapturedRequests(args[0], target)
+		},
+	}
+
+	cmd.Flags().StringVar(&target, "target", "", "Target base URL. Default is base_url from settings.")
+
+	return cmd
+}
+
+func (app *AppBase) buildRoutesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "routes",
+		Short: "Prints all registered web routes with methods and handler names.",
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			//build router without listening
+			app.Handler()
+
+			if app.ginEngine == nil {
+				return errors.New("custom web handler is set, routes are not available")
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+			fmt.Fprintln(w, "METHOD\tPATH\tHANDLER")
+			for _, route := range app.ginEngine.Routes() {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", route.Method, route.Path, route.Handler)
+			}
+
+			//API handlers are served by single wildcard route, so list them separately
+			//methods are the ones API handler checks (see webApiRequestGinHandler())
+			for _, path := range slices.Sorted(maps.Keys(app.webApiHandlerList)) {
+				methods := strings.Join(app.webApiAllowedMethods(path), ",")
+				handlerName := runtime.FuncForPC(reflect.ValueOf(app.webApiHandlerList[path]).Pointer()).Name()
+				fmt.Fprintf(w, "%s\t%s\t%s\n", methods, app.WebApiPathPrefix+path, handlerName)
+			}
+
+			return w.Flush()
+		},
+	}
+}
+
+func (app *AppBase) buildConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Settings inspection commands.",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "origin <field>",
+		Short: "Prints settings field effective value and source it was taken from (file, flag, default).",
+		Long:  "Prints settings field effective value and source it was taken from (file, flag, default).\nField is a yaml key or field name, dot-separated for nested settings.",
+		Args:  cobra.ExactArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return app.printSettingsOrigin(args[0])
+		},
+	})
+
+	return cmd
+}
+
+func (app *AppBase) buildRotateSecretCmd() *cobra.Command {
+	var keep int
+
+	cmd := &cobra.Command{
+		Use:   "rotate-secret",
+		Short: "Generates new cookie secret keeping current one as previous (sessions stay valid).",
+		Long: "Generates new webserver_cookie_secret and moves current one to webserver_cookie_previous_secrets, " +
+			"so sessions signed with it are still accepted.\nRestart the service to apply new secret.",
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := rotateCookieSecret(app.AppSettingsFilename, keep); err != nil {
+				return err
+			}
+
+			fmt.Println("Cookie secret rotated in " + app.AppSettingsFilename)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&keep, "keep", 3, "Number of previous secrets to keep (older ones are removed).")
+
+	return cmd
+}
+
+func (app *AppBase) buildCheckConnectivityCmd() *cobra.Command {
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "check-connectivity",
+		Short: "Checks app dependencies (database, connectivity_check_targets, app checks) are reachable.",
+		Long: "Tries to connect to database, tracing collector, connectivity_check_targets setting items and app-specific " +
+			"dependencies printing result and latency for each of them.\nExits with error if any check failed.",
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return app.runConnectivityChecks(timeout)
+		},
+	}
+
+	cmd.Flags().DurationVar(&timeout, "timeout", 5*time.Second, "Timeout for every single check.")
+
+	return cmd
+}
+
+func (app *AppBase) buildMigrateCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Migrates database schema for all registered models.",
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if DbSchema.Db() == nil {
+				if err := DbSchema.connect(app.baseSettings.LogSql); err != nil {
+					return err
+				}
+
+				defer DbSchema.Close()
+			}
+
+			if !dryRun {
+				if DbSchema.isReadOnly() {
+					return ErrDatabaseReadOnly
+				}
+
+				return DbSchema.migrate()
+			}
+
+			list, err := DbSchema.schemaStatus()
+			if err != nil {
+				return err
+			}
+
+			if pending := printDbSchemaStatus(list); pending > 0 {
+				fmt.Printf("\nDry run: %d of %d models require migration, nothing was changed\n", pending, len(list))
+			} else {
+				fmt.Printf("\nDry run: all %d models are up to date\n", len(list))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print pending schema changes without applying them.")
+
+	return cmd
+}
+
+func (app *AppBase) buildDbCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Database maintenance commands.",
+	}
+
+	cmd.AddCommand(
+		app.buildDbStatusCmd(),
+		app.buildDbBackupCmd(),
+		app.buildDbBenchCmd(),
+	)
+
+	return cmd
+}
+
+func (app *AppBase) buildDbStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Reports registered models schema state: missing tables, columns and indexes. Does not migrate anything.",
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if DbSchema.Db() == nil {
+				if err := DbSchema.connect(app.baseSettings.LogSql); err != nil {
+					return err
+				}
+
+				defer DbSchema.Close()
+			}
+
+			list, err := DbSchema.schemaStatus()
+			if err != nil {
+				return err
+			}
+
+			if pending := printDbSchemaStatus(list); pending > 0 {
+				fmt.Printf("\n%d of %d models require migration\n", pending, len(list))
+			} else {
+				fmt.Printf("\nAll %d models are up to date\n", len(list))
+			}
+
+			return nil
+		},
+	}
+}
+
+func (app *AppBase) buildDbBackupCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "backup <path>",
+		Short: "Writes consistent copy of sqlite database to path (with VACUUM INTO, safe for running app).",
+		Args:  cobra.ExactArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if DbSchema.driver() != dbDriverSqlite {
+				return fmt.Errorf("backup is supported for sqlite database only, database_driver is '%s'", DbSchema.driver())
+			}
+
+			if mttools.IsFileExists(args[0]) {
+				return errors.New("backup file already exists: " + args[0])
+			}
+
+			if DbSchema.Db() == nil {
+				if err := DbSchema.connect(app.baseSettings.LogSql); err != nil {
+					return err
+				}
+
+				defer DbSchema.Close()
+			}
+
+			// VACUUM INTO reads database in single transaction, so copy is consistent
+			if err := DbSchema.Db().Exec("VACUUM INTO ?", args[0]).Error; err != nil {
+				return err
+			}
+
+			fmt.Printf("Database %s backed up to %s\n", DbSchema.name(), args[0])
+
+			return nil
+		},
+	}
+}
+
+func (app *AppBase) buildDbBenchCmd() *cobra.Command {
+	var count int
+
+	cmd := &cobra.Command{
+		Use:    "bench",
+		Short:  "Runs simple database benchmark using temporary table.",
+		Hidden: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if DbSchema.Db() == nil {
+				if err := DbSchema.connect(app.baseSettings.LogSql); err != nil {
+					return err
+				}
+
+				defer DbSchema.Close()
+			}
+
+			return runDbBenchmark(DbSchema.Db(), count)
+		},
+	}
+
+	cmd.Flags().IntVar(&count, "count", 1000, "Number of operations of each type.")
+
+	return cmd
+}
+
+// Converts timeout setting to http.Server timeout value. Zero setting means no timeout, which is
+// negative value for http.Server (zero IdleTimeout falls back to ReadTimeout).
+func webserverTimeout(seconds uint) time.Duration {
+	if seconds == 0 {
+		return -1
+	}
+
+	return time.Duration(seconds) * time.Second
+}
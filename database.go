@@ -1,48 +1,116 @@
 package goapp
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"maps"
 	"os"
+	"path/filepath"
 	"reflect"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/glebarez/sqlite"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/mitoteam/mttools"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	gorm "gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	"gorm.io/gorm/schema"
 )
 
-const dbFileName = "data.db"
+// sqlite database file name used if database_file setting is empty
+const dbDefaultFileName = "data.db"
+
+// supported database_driver setting values
+const (
+	dbDriverSqlite   = "sqlite"
+	dbDriverPostgres = "postgres"
+	dbDriverMysql    = "mysql"
+)
+
+// advisory lock name used to serialize schema migrations between app instances
+const dbMigrationLockName = "goapp_schema_migration"
+
+var dbNamingStrategy = schema.NamingStrategy{
+	SingularTable: true, // use singular table name, table for `User` would be `user` with this option enabled
+}
 
 type dbSchemaType struct {
-	modelMap map[string]any // name = typename, value = empty struct of this type
-	db       *gorm.DB
+	modelMap  map[string]any // name = typename, value = empty struct of this type
+	db        *gorm.DB
+	noMigrate bool // skip schema migration in Open() (--no-migrate option of `run`)
+
+	settings *AppSettingsBase // app base settings (set by NewAppBase())
 }
 
+// Returned for write operations when database is opened in read-only mode.
+var ErrDatabaseReadOnly = errors.New("database is opened in read-only mode (database_read_only setting)")
+
 var DbSchema *dbSchemaType
 
 func init() {
 	DbSchema = &dbSchemaType{}
 
 	DbSchema.modelMap = make(map[string]any, 0) //typeName => modelObject
+
+	for _, modelType := range dbBuiltInModels {
+		DbSchema.AddModel(modelType)
+	}
+}
+
+// models used by goapp itself (WithLock() etc)
+var dbBuiltInModels = []reflect.Type{
+	reflect.TypeFor[dbLock](),
 }
 
 func (schema *dbSchemaType) AddModel(modelType reflect.Type) {
 	//ensure it is a struct
 	if modelType.Kind() != reflect.Struct {
-		log.Panicf("modelType %s is not a struct", modelType.String())
+		logPanicf("modelType %s is not a struct", modelType.String())
 	}
 
-	//ensure it embeds DbModel
-	if !mttools.IsStructTypeEmbeds(modelType, reflect.TypeFor[DbModel]()) {
-		log.Panicf("modelType %s does not embed DbModel", modelType.String())
+	//ensure it embeds DbModel or BaseModel
+	if !mttools.IsStructTypeEmbeds(modelType, reflect.TypeFor[schemaModel]()) {
+		logPanicf("modelType %s does not embed DbModel or BaseModel", modelType.String())
+	}
+
+	//ensure gorm is able to parse it and resolve table name (to fail here and not in migration)
+	if table, err := dbModelTableName(modelType); err != nil {
+		logPanicf("modelType %s is not a valid model: %s", modelType.String(), err.Error())
+	} else if table == "" {
+		logPanicf("modelType %s: unable to resolve table name (anonymous struct?)", modelType.String())
 	}
 
 	//crate empty model object
 	schema.modelMap[modelType.String()] = reflect.New(modelType).Elem().Interface()
 }
 
+// Registers model type T in DbSchema. Same as DbSchema.AddModel(reflect.TypeFor[T]()).
+func AddModel[T any]() {
+	DbSchema.AddModel(reflect.TypeFor[T]())
+}
+
+// Resolves model table name using same naming strategy as Open() does.
+func dbModelTableName(modelType reflect.Type) (string, error) {
+	modelSchema, err := schema.Parse(reflect.New(modelType).Interface(), &sync.Map{}, dbNamingStrategy)
+	if err != nil {
+		return "", err
+	}
+
+	return modelSchema.Table, nil
+}
+
+// Returns number of models registered by app (built-in ones are not counted).
+func (schema *dbSchemaType) appModelCount() int {
+	return len(schema.modelMap) - len(dbBuiltInModels)
+}
+
 func (schema *dbSchemaType) HasModel(modelType reflect.Type) bool {
 	_, exists := schema.modelMap[modelType.String()]
 	return exists
@@ -52,56 +120,373 @@ func (schema *dbSchemaType) Db() *gorm.DB {
 	return schema.db
 }
 
+// Opens database and migrates schema for all registered models (unless --no-migrate option given).
 func (db_schema *dbSchemaType) Open(logSql bool) error {
+	if err := db_schema.connect(logSql); err != nil {
+		return err
+	}
+
+	if db_schema.isReadOnly() {
+		logInfof("Database migration skipped in read-only mode")
+		return nil
+	}
+
+	if db_schema.noMigrate {
+		logInfof("Database migration skipped, schema is assumed to be up to date")
+		return nil
+	}
+
+	return db_schema.migrate()
+}
+
+// Opens database connection without schema migration.
+func (db_schema *dbSchemaType) connect(logSql bool) error {
 	var err error
 
 	config := &gorm.Config{
 		//Logger: logger.Default.LogMode(logger.Warn),
-		NamingStrategy: schema.NamingStrategy{
-			SingularTable: true, // use singular table name, table for `User` would be `user` with this option enabled
-		},
+		NamingStrategy: dbNamingStrategy,
 	}
 
-	gormLogger := logger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), logger.Config{
-		SlowThreshold:             500 * time.Millisecond,
-		IgnoreRecordNotFoundError: true,
-		Colorful:                  true,
-	})
+	var gormLogger logger.Interface
+
+	if db_schema.settings != nil && db_schema.settings.LogFormat == LogFormatJson {
+		// standard logger writes JSON already (see setupLogging())
+		gormLogger = logger.New(log.Default(), logger.Config{
+			SlowThreshold:             500 * time.Millisecond,
+			IgnoreRecordNotFoundError: true,
+			LogLevel:                  gormLogLevel(),
+		})
+	} else {
+		gormLogger = logger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), logger.Config{
+			SlowThreshold:             500 * time.Millisecond,
+			IgnoreRecordNotFoundError: true,
+			Colorful:                  true,
+			LogLevel:                  gormLogLevel(),
+		})
+	}
 
 	if logSql {
-		gormLogger.LogMode(logger.Info)
+		gormLogger = gormLogger.LogMode(logger.Info)
 	}
 
 	config.Logger = gormLogger
 
-	db_schema.db, err = gorm.Open(sqlite.Open(dbFileName), config)
+	dialector, err := db_schema.dialector()
+	if err != nil {
+		return err
+	}
+
+	if err := db_schema.ensureDataDir(); err != nil {
+		return err
+	}
+
+	db, err := gorm.Open(dialector, config)
 
 	if err != nil {
+		return fmt.Errorf("unable to open %s database: %w", db_schema.name(), err)
+	}
+
+	if db_schema.settings != nil && db_schema.settings.TracingOtlpEndpoint != "" {
+		if err := registerTracingCallbacks(db); err != nil {
+			return err
+		}
+	}
+
+	if err := db_schema.configurePool(db); err != nil {
 		return err
 	}
 
-	log.Printf("Database %s opened\n", dbFileName)
+	db_schema.db = db
 
-	// Migrate the schema
-	for name, modelObject := range db_schema.modelMap {
-		if err := db_schema.db.AutoMigrate(modelObject); err != nil {
-			log.Panicf("ERROR migrating %s: %s\n", name, err.Error())
+	if db_schema.isReadOnly() {
+		if err := registerReadOnlyCallbacks(db_schema.db); err != nil {
+			return err
 		}
+
+		logInfof("Database %s opened in read-only mode", db_schema.name())
+	} else {
+		logInfof("Database %s opened", db_schema.name())
+	}
+
+	return nil
+}
+
+// Returns gorm dialector for database_driver setting. sqlite is used by default.
+func (db_schema *dbSchemaType) dialector() (gorm.Dialector, error) {
+	switch db_schema.driver() {
+	case dbDriverSqlite:
+		dsn := db_schema.fileName()
+
+		if db_schema.isReadOnly() {
+			dsn = sqliteReadOnlyDsn(dsn)
+		}
+
+		return sqlite.Open(dsn), nil
+
+	case dbDriverPostgres:
+		if db_schema.settings.DatabaseDsn == "" {
+			return nil, errors.New("database_dsn setting is required for postgres database driver")
+		}
+
+		if db_schema.isReadOnly() {
+			return postgresReadOnlyDialector(db_schema.settings.DatabaseDsn)
+		}
+
+		return postgres.Open(db_schema.settings.DatabaseDsn), nil
+
+	case dbDriverMysql:
+		if db_schema.settings.DatabaseDsn == "" {
+			return nil, errors.New("database_dsn setting is required for mysql database driver")
+		}
+
+		return mysql.Open(db_schema.settings.DatabaseDsn), nil
+
+	default:
+		return nil, fmt.Errorf(
+			"unknown database_driver '%s' (supported: %s, %s, %s)",
+			db_schema.driver(), dbDriverSqlite, dbDriverPostgres, dbDriverMysql,
+		)
+	}
+}
+
+func (db_schema *dbSchemaType) driver() string {
+	if db_schema.settings == nil || db_schema.settings.DatabaseDriver == "" {
+		return dbDriverSqlite
+	}
+
+	return db_schema.settings.DatabaseDriver
+}
+
+// sqlite database file path
+func (db_schema *dbSchemaType) fileName() string {
+	if db_schema.settings == nil || db_schema.settings.DatabaseFile == "" {
+		return dbDefaultFileName
+	}
+
+	return db_schema.settings.DatabaseFile
+}
+
+// Creates sqlite database file directory if it is missing (usual first run issue for installed service).
+func (db_schema *dbSchemaType) ensureDataDir() error {
+	if db_schema.driver() != dbDriverSqlite || strings.HasPrefix(db_schema.fileName(), "file:") {
+		return nil // not a file or URI filename (in-memory database)
+	}
+
+	dir := filepath.Dir(db_schema.fileName())
+
+	if mttools.IsDirExists(dir) {
+		return nil
+	}
+
+	serviceUser := ""
+	if db_schema.settings != nil {
+		serviceUser = db_schema.settings.ServiceUser
+	}
+
+	if db_schema.isReadOnly() {
+		return fmt.Errorf("database directory %s does not exist", dir)
+	}
+
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf(
+			"database directory %s does not exist and can not be created (%w). "+
+				"Create it and make it writable for service user '%s'", dir, err, serviceUser,
+		)
 	}
 
-	log.Printf("Database migration done (schema model count: %d)\n", len(db_schema.modelMap))
+	logInfof("Database directory %s created", dir)
 
 	return nil
 }
 
-func (schema *dbSchemaType) Close() {
-	sqlDB, err := schema.db.DB()
+// Database name for log messages (DSN is not logged, it can contain password)
+func (db_schema *dbSchemaType) name() string {
+	if db_schema.driver() == dbDriverSqlite {
+		return db_schema.fileName()
+	}
+
+	return db_schema.driver()
+}
+
+// Applies connection pool settings. Zero values leave driver defaults.
+func (db_schema *dbSchemaType) configurePool(db *gorm.DB) error {
+	if db_schema.settings == nil {
+		return nil
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	if db_schema.settings.DatabaseMaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(db_schema.settings.DatabaseMaxOpenConns)
+	}
+
+	if db_schema.settings.DatabaseMaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(db_schema.settings.DatabaseMaxIdleConns)
+	}
+
+	if db_schema.settings.DatabaseConnMaxLifetimeSeconds > 0 {
+		sqlDB.SetConnMaxLifetime(time.Duration(db_schema.settings.DatabaseConnMaxLifetimeSeconds) * time.Second)
+	}
+
+	return nil
+}
+
+// Opens sqlite file in read-only mode and makes connection reject writes (raw SQL too).
+func sqliteReadOnlyDsn(dsn string) string {
+	if !strings.HasPrefix(dsn, "file:") {
+		dsn = "file:" + dsn
+	}
 
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+
+	return dsn + separator + "mode=ro&_pragma=query_only(1)"
+}
+
+// Postgres connections with read-only transactions by default (raw SQL writes are rejected by server too).
+func postgresReadOnlyDialector(dsn string) (gorm.Dialector, error) {
+	config, err := pgx.ParseConfig(dsn)
 	if err != nil {
-		sqlDB.Close()
+		return nil, err
+	}
+
+	config.RuntimeParams["default_transaction_read_only"] = "on"
+
+	return postgres.New(postgres.Config{Conn: stdlib.OpenDB(*config)}), nil
+}
+
+func (db_schema *dbSchemaType) isReadOnly() bool {
+	return db_schema.settings != nil && db_schema.settings.DatabaseReadOnly
+}
+
+// Makes gorm reject all write operations before they reach database. Raw SQL (Exec()) is not checked
+// here, it is rejected by read-only sqlite file and postgres session (not for mysql).
+func registerReadOnlyCallbacks(db *gorm.DB) error {
+	rejectF := func(tx *gorm.DB) {
+		tx.AddError(ErrDatabaseReadOnly)
 	}
 
-	log.Printf("Database %s closed\n", dbFileName)
+	callbacks := db.Callback()
+
+	if err := callbacks.Create().Before("gorm:create").Register("goapp:read_only", rejectF); err != nil {
+		return err
+	}
+
+	if err := callbacks.Update().Before("gorm:update").Register("goapp:read_only", rejectF); err != nil {
+		return err
+	}
+
+	return callbacks.Delete().Before("gorm:delete").Register("goapp:read_only", rejectF)
+}
+
+// single model migration taking longer is reported with warning
+const dbSlowMigrationThreshold = 10 * time.Second
+
+// Migrates schema for all registered models.
+func (db_schema *dbSchemaType) migrate() error {
+	logInfof("Database migration started (schema model count: %d)", len(db_schema.modelMap))
+	start := time.Now()
+
+	err := db_schema.withMigrationLock(func(tx *gorm.DB) error {
+		for _, name := range slices.Sorted(maps.Keys(db_schema.modelMap)) {
+			modelStart := time.Now()
+
+			if err := tx.AutoMigrate(db_schema.modelMap[name]); err != nil {
+				return fmt.Errorf("%s migration failed: %w", name, err)
+			}
+
+			elapsed := time.Since(modelStart)
+
+			if elapsed > dbSlowMigrationThreshold {
+				logWarnf("%s migration is slow: %s", name, elapsed.Round(time.Millisecond))
+			} else {
+				logDebugf("%s migrated in %s", name, elapsed.Round(time.Millisecond))
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	logInfof(
+		"Database migration done in %s (schema model count: %d)\n",
+		time.Since(start).Round(time.Millisecond), len(db_schema.modelMap),
+	)
+
+	return nil
+}
+
+// Calls migrateF holding database level advisory lock, so only one app instance migrates
+// schema at a time. Others wait for the lock and proceed after it is released.
+// sqlite has no advisory locks (and no concurrent instances with single file), so migrateF is just called.
+func (db_schema *dbSchemaType) withMigrationLock(migrateF func(tx *gorm.DB) error) error {
+	// advisory locks are bound to connection, so use single one for lock, migration and unlock
+	return db_schema.db.Connection(func(tx *gorm.DB) error {
+		switch tx.Dialector.Name() {
+		case "postgres":
+			if err := tx.Exec("SELECT pg_advisory_lock(hashtext(?))", dbMigrationLockName).Error; err != nil {
+				return err
+			}
+
+			defer tx.Exec("SELECT pg_advisory_unlock(hashtext(?))", dbMigrationLockName)
+
+		case "mysql":
+			var acquired int
+
+			// negative timeout = wait forever
+			if err := tx.Raw("SELECT GET_LOCK(?, -1)", dbMigrationLockName).Scan(&acquired).Error; err != nil {
+				return err
+			}
+
+			if acquired != 1 {
+				return errors.New("unable to acquire migration lock " + dbMigrationLockName)
+			}
+
+			defer tx.Exec("SELECT RELEASE_LOCK(?)", dbMigrationLockName)
+		}
+
+		return migrateF(tx)
+	})
+}
+
+// Runs fn in database transaction. Transaction is rolled back if fn returns error or panics.
+// Panics if database is not opened.
+func (schema *dbSchemaType) Transaction(fn func(tx *gorm.DB) error) error {
+	if schema.db == nil {
+		logPanicf("Transaction(): database is not opened")
+	}
+
+	return schema.db.Transaction(fn)
+}
+
+// Closes database connection (if opened).
+func (schema *dbSchemaType) Close() error {
+	if schema.db == nil {
+		return nil
+	}
+
+	sqlDB, err := schema.db.DB()
+
+	if err == nil {
+		err = sqlDB.Close()
+	}
 
 	schema.db = nil
+
+	if err != nil {
+		return fmt.Errorf("database %s close error: %w", schema.name(), err)
+	}
+
+	logInfof("Database %s closed", schema.name())
+
+	return nil
 }
@@ -0,0 +1,103 @@
+package goapp
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	gorm "gorm.io/gorm"
+)
+
+// Temporary table record for database benchmark
+type dbBenchRecord struct {
+	ID    int64 `gorm:"primaryKey"`
+	Name  string
+	Value int64
+}
+
+func (dbBenchRecord) TableName() string {
+	return "goapp_bench"
+}
+
+// Runs count insert, select and update operations against temporary table and prints
+// throughput and latency percentiles for each operation type.
+func runDbBenchmark(db *gorm.DB, count int) error {
+	if count < 1 {
+		return fmt.Errorf("operations count should be positive, %d given", count)
+	}
+
+	migrator := db.Migrator()
+
+	if migrator.HasTable(&dbBenchRecord{}) {
+		if err := migrator.DropTable(&dbBenchRecord{}); err != nil {
+			return err
+		}
+	}
+
+	if err := migrator.CreateTable(&dbBenchRecord{}); err != nil {
+		return err
+	}
+	defer migrator.DropTable(&dbBenchRecord{})
+
+	fmt.Printf("Database benchmark: %d operations of each type (%s)\n\n", count, db.Dialector.Name())
+
+	ids := make([]int64, 0, count)
+
+	err := benchDbOperation("insert", count, func(i int) error {
+		record := &dbBenchRecord{Name: fmt.Sprintf("record %d", i), Value: int64(i)}
+
+		if err := db.Create(record).Error; err != nil {
+			return err
+		}
+
+		ids = append(ids, record.ID)
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	err = benchDbOperation("select", count, func(i int) error {
+		var record dbBenchRecord
+		return db.First(&record, ids[i]).Error
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return benchDbOperation("update", count, func(i int) error {
+		return db.Model(&dbBenchRecord{ID: ids[i]}).Update("value", i*2).Error
+	})
+}
+
+// Calls opF count times measuring each call latency, prints results.
+func benchDbOperation(name string, count int, opF func(i int) error) error {
+	latencies := make([]time.Duration, count)
+	start := time.Now()
+
+	for i := 0; i < count; i++ {
+		opStart := time.Now()
+
+		if err := opF(i); err != nil {
+			return fmt.Errorf("%s #%d failed: %w", name, i, err)
+		}
+
+		latencies[i] = time.Since(opStart)
+	}
+
+	total := time.Since(start)
+	slices.Sort(latencies)
+
+	percentile := func(p int) time.Duration {
+		return latencies[min(count-1, count*p/100)]
+	}
+
+	fmt.Printf(
+		"%-7s %8.1f ops/s  p50: %-10s p95: %-10s p99: %-10s max: %s\n",
+		name, float64(count)/total.Seconds(), percentile(50), percentile(95), percentile(99), latencies[count-1],
+	)
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package goapp
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mitoteam/mttools"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Returned by WithLock() when lock is held by someone else.
+var ErrLockNotAcquired = errors.New("lock is held by another owner")
+
+// Row-based lock record. Expired locks are removed by next acquirer, so lock does not stay
+// forever if owner instance crashed. Registered as built-in model, so table is created by migration.
+type dbLock struct {
+	schemaModel
+
+	Name      string `gorm:"primaryKey;size:190"`
+	Owner     string `gorm:"size:100;not null"`
+	ExpiresAt time.Time
+}
+
+func (dbLock) TableName() string {
+	return "goapp_lock"
+}
+
+// Runs fn only if named database lock was acquired. Lock is released after fn returns.
+// ttl limits time lock is held if owner crashed without releasing it, lock is renewed while fn runs.
+// Returns ErrLockNotAcquired if lock is held by another instance.
+func (app *AppBase) WithLock(name string, ttl time.Duration, fn func() error) error {
+	db := DbSchema.Db()
+
+	if db == nil {
+		return errors.New("database is not opened")
+	}
+
+	owner := app.lockOwnerName()
+
+	//remove expired lock (if any)
+	if err := db.Where("name = ? AND expires_at < ?", name, time.Now()).Delete(&dbLock{}).Error; err != nil {
+		return err
+	}
+
+	lock := &dbLock{Name: name, Owner: owner, ExpiresAt: time.Now().Add(ttl)}
+
+	// nothing is inserted if lock record exists already
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(lock)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrLockNotAcquired
+	}
+
+	defer db.Where("name = ? AND owner = ?", name, owner).Delete(&dbLock{})
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		renewDbLock(db, name, owner, ttl, stop)
+	}()
+
+	defer func() {
+		close(stop)
+		wg.Wait()
+	}()
+
+	return fn()
+}
+
+// Extends lock expiration time while its owner works (until stop is closed).
+func renewDbLock(db *gorm.DB, name, owner string, ttl time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(max(ttl/3, time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+
+		case <-ticker.C:
+			result := db.Model(&dbLock{}).Where("name = ? AND owner = ?", name, owner).
+				Update("expires_at", time.Now().Add(ttl))
+
+			if result.Error != nil {
+				logErrorf("Lock %s renewal ERROR: %s", name, result.Error)
+			} else if result.RowsAffected == 0 {
+				logErrorf("Lock %s was lost (taken by another owner after expiration)", name)
+				return
+			}
+		}
+	}
+}
+
+// Unique name of lock owner (this app instance and call)
+func (app *AppBase) lockOwnerName() string {
+	hostname, _ := os.Hostname()
+
+	return hostname + ":" + strconv.Itoa(os.Getpid()) + ":" + mttools.RandomString(16)
+}
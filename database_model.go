@@ -2,28 +2,69 @@ package goapp
 
 import (
 	"errors"
-	"log"
+	"fmt"
 	"reflect"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/mitoteam/mttools"
+	"github.com/google/uuid"
 	gorm "gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
 )
 
-// base model type all model types should embed
+// marker of schema model types (embedded to both DbModel and BaseModel)
+type schemaModel struct {
+}
+
+// base model type with primary key, timestamps and Soft Delete feature (deleted_at column with index).
+// All model types should embed DbModel or BaseModel.
+// see https://gorm.io/docs/delete.html#Soft-Delete
 type DbModel struct {
+	schemaModel
+
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
 
 // gorm.Model alternative without DeletedAt column (to disable Soft Delete feature)
 // see https://gorm.io/docs/delete.html#Soft-Delete
 type BaseModel struct {
-	DbModel
+	schemaModel
 
 	ID        int64 `gorm:"primaryKey;not null"`
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
+// DbModel alternative with UUID string primary key (generated automatically on create if not set).
+// Use it when sequential integer IDs should not be exposed or records are merged between databases.
+type DbModelUUID struct {
+	schemaModel
+
+	ID        string `gorm:"primaryKey;type:varchar(36)"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+// gorm hook generating ID for new records
+func (m *DbModelUUID) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == "" {
+		m.ID = uuid.NewString()
+	}
+
+	return nil
+}
+
+// Primary key types supported by generic helpers (Load(), LoadO(), LoadWith(), Save(), Delete() etc)
+type ModelIdType interface {
+	~uint | ~int | ~int64 | ~string
+}
+
 // gorm TX object. Prepared in PreQuery(), used in LoadObject, LoadOL, CountOL
 var gormTx *gorm.DB
 
@@ -40,13 +81,18 @@ func PreQuery[ModelT any]() (tx *gorm.DB) {
 	return gormTx
 }
 
-// Loads model object by ID. Returns nil if object was not loaded.
-func LoadO[ModelT any](id any) (r *ModelT) {
-	defer func() { gormTx = nil }()
+// Primary key condition for typed ID. Explicit condition is required: plain string argument would be
+// taken by gorm as SQL condition.
+func primaryKeyCondition[IdT ModelIdType](id IdT) clause.Eq {
+	return clause.Eq{Column: clause.PrimaryColumn, Value: id}
+}
 
-	typedId, ok := mttools.AnyToInt64Ok(id)
+// Loads model object by primary key (integer or string). Returns nil if object was not loaded.
+func LoadO[ModelT any, IdT ModelIdType](id IdT) (r *ModelT) {
+	defer func() { gormTx = nil }()
 
-	if !ok || typedId == 0 { //id is empty
+	var zeroId IdT
+	if id == zeroId { //id is empty
 		return nil
 	}
 
@@ -58,9 +104,9 @@ func LoadO[ModelT any](id any) (r *ModelT) {
 
 	var modelObject ModelT
 
-	if err := gormTx.First(&modelObject, typedId).Error; err != nil {
+	if err := gormTx.First(&modelObject, primaryKeyCondition(id)).Error; err != nil {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Println("Query ERROR: " + err.Error())
+			logErrorf("Query ERROR: %s", err)
 		}
 
 		return nil
@@ -69,29 +115,114 @@ func LoadO[ModelT any](id any) (r *ModelT) {
 	return &modelObject
 }
 
+// Loads model object by primary key (integer or UUID string). Returns nil if object was not found.
+// Panics if ModelT was not registered with AddModel() (to catch typos early) or database is not opened.
+func Load[ModelT any, IdT ModelIdType](id IdT) *ModelT {
+	modelType := reflect.TypeFor[ModelT]()
+
+	if !DbSchema.HasModel(modelType) {
+		logPanicf("Load(): model %s is not registered with AddModel()", modelType.String())
+	}
+
+	if DbSchema.Db() == nil {
+		logPanicf("Load(): database is not opened")
+	}
+
+	var zeroId IdT
+	if id == zeroId {
+		return nil
+	}
+
+	var modelObject ModelT
+
+	if err := DbSchema.Db().First(&modelObject, primaryKeyCondition(id)).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logErrorf("Query ERROR: %s", err)
+		}
+
+		return nil
+	}
+
+	return &modelObject
+}
+
+// Loads model object by primary key (integer or string) with associations preloaded. preloads are
+// association paths ("Author", "Comments.Author"). Paths are validated against model to catch typos early.
+// Returns error if object was not found.
+func LoadWith[ModelT any, IdT ModelIdType](id IdT, preloads ...string) (r *ModelT, err error) {
+	defer func() { gormTx = nil }()
+
+	var zeroId IdT
+	if id == zeroId {
+		return nil, fmt.Errorf("invalid id: %v", id)
+	}
+
+	modelType := reflect.TypeFor[ModelT]()
+
+	for _, path := range preloads {
+		if err := checkPreloadPath(modelType, path); err != nil {
+			return nil, err
+		}
+	}
+
+	if gormTx == nil { // gormTx is not prepared
+		if gormTx = PreQuery[ModelT](); gormTx == nil { //unable to prepare
+			return nil, fmt.Errorf("unable to query model %s", modelType.String())
+		}
+	}
+
+	for _, path := range preloads {
+		gormTx = gormTx.Preload(path)
+	}
+
+	var modelObject ModelT
+
+	if err := gormTx.First(&modelObject, primaryKeyCondition(id)).Error; err != nil {
+		return nil, err
+	}
+
+	return &modelObject, nil
+}
+
+// Checks that every dot-separated part of preload path is a model relationship.
+func checkPreloadPath(modelType reflect.Type, path string) error {
+	modelSchema, err := schema.Parse(reflect.New(modelType).Interface(), &sync.Map{}, dbNamingStrategy)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range strings.Split(path, ".") {
+		relationship, ok := modelSchema.Relationships.Relations[name]
+
+		if !ok {
+			return fmt.Errorf("model %s has no association '%s' (preload path '%s')", modelSchema.Name, name, path)
+		}
+
+		modelSchema = relationship.FieldSchema
+	}
+
+	return nil
+}
+
 // Works like LoadO() but panics if object was not found.
-func LoadOMust[ModelT any](id any) (r *ModelT) {
+func LoadOMust[ModelT any, IdT ModelIdType](id IdT) (r *ModelT) {
 	r = LoadO[ModelT](id)
 
 	if r == nil {
-		log.Panicf("Can not load model object %s[ID=%v]", reflect.TypeFor[ModelT]().String(), id)
+		logPanicf("Can not load model object %s[ID=%v]", reflect.TypeFor[ModelT]().String(), id)
 	}
 
 	return r
 }
 
-// If id == 0 creates new empty object. Loads model object by ID otherwise.
-func LoadOrCreateO[ModelT any](id any) (r *ModelT) {
-	typedId, ok := mttools.AnyToInt64Ok(id)
+// If id is empty (0 or "") creates new empty object. Loads model object by ID otherwise.
+func LoadOrCreateO[ModelT any, IdT ModelIdType](id IdT) (r *ModelT) {
+	var zeroId IdT
 
-	if !ok {
-		return nil // id type is unknown
-	}
-
-	if typedId == 0 {
+	if id == zeroId {
 		return new(ModelT)
 	} else {
-		return LoadO[ModelT](typedId)
+		return LoadO[ModelT](id)
 	}
 }
 
@@ -110,7 +241,7 @@ func FirstO[ModelT any]() (r *ModelT) {
 
 	if err := gormTx.First(&modelObject).Error; err != nil {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Println("Query ERROR: " + err.Error())
+			logErrorf("Query ERROR: %s", err)
 		}
 
 		return nil
@@ -129,7 +260,7 @@ func DeleteObject(modelObject any) error {
 
 	//make sure it is saved object (with ID set)
 	v := reflect.ValueOf(modelObject).Elem() // struct itself from pointer
-	if v.FieldByName(reflect.TypeFor[BaseModel]().Name()).FieldByName("ID").Int() == 0 {
+	if v.FieldByName("ID").IsZero() {
 		return errors.New("modelObject has ID=0")
 	}
 
@@ -137,7 +268,7 @@ func DeleteObject(modelObject any) error {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil
 		} else {
-			log.Println("Query ERROR: " + err.Error())
+			logErrorf("Query ERROR: %s", err)
 			return err
 		}
 	}
@@ -145,6 +276,134 @@ func DeleteObject(modelObject any) error {
 	return nil
 }
 
+// Deletes all model objects matching query conditions in a transaction. Returns number of deleted rows.
+// Models with gorm.DeletedAt field are soft-deleted (use DeleteWhereUnscoped() to remove rows physically).
+// If query is nil, gorm TX prepared with PreQuery() is used.
+// Query without WHERE conditions is refused to prevent accidental full table removal.
+func DeleteWhere[ModelT any](query *gorm.DB) (int64, error) {
+	return deleteWhere[ModelT](query, false)
+}
+
+// Works like DeleteWhere() but removes rows physically even for soft-delete models.
+func DeleteWhereUnscoped[ModelT any](query *gorm.DB) (int64, error) {
+	return deleteWhere[ModelT](query, true)
+}
+
+func deleteWhere[ModelT any](query *gorm.DB, unscoped bool) (affected int64, err error) {
+	defer func() { gormTx = nil }()
+
+	if !checkSchemaModelType(reflect.TypeFor[ModelT]()) {
+		return 0, errors.New("not a valid schema model type")
+	}
+
+	if query == nil {
+		query = gormTx
+	}
+
+	if query == nil {
+		return 0, errors.New("query is not prepared")
+	}
+
+	if !hasWhereConditions(query) {
+		return 0, errors.New("refusing to delete without WHERE conditions")
+	}
+
+	err = DbSchema.Db().Transaction(func(tx *gorm.DB) error {
+		tx = tx.Where(query) // query conditions as a group
+
+		if unscoped {
+			tx = tx.Unscoped()
+		}
+
+		result := tx.Delete(new(ModelT))
+		affected = result.RowsAffected
+
+		return result.Error
+	})
+
+	if err != nil {
+		logErrorf("Query ERROR: %s", err)
+		return 0, err
+	}
+
+	return affected, nil
+}
+
+// Checks if query has at least one WHERE condition
+func hasWhereConditions(query *gorm.DB) bool {
+	whereClause, ok := query.Statement.Clauses["WHERE"]
+	if !ok {
+		return false
+	}
+
+	where, ok := whereClause.Expression.(clause.Where)
+
+	return ok && len(where.Exprs) > 0
+}
+
+// Inserts model object if its ID is not set yet or updates it otherwise. ID and timestamps
+// are populated on success. Returns error if database is not opened or model is not registered.
+func Save[ModelT any](modelObject *ModelT) error {
+	modelType := reflect.TypeFor[ModelT]()
+
+	if DbSchema.Db() == nil {
+		return errors.New("database is not opened")
+	}
+
+	if !DbSchema.HasModel(modelType) {
+		return fmt.Errorf("model %s is not registered with AddModel()", modelType.String())
+	}
+
+	if modelObject == nil {
+		return errors.New("modelObject is nil")
+	}
+
+	var err error
+
+	if reflect.ValueOf(modelObject).Elem().FieldByName("ID").IsZero() {
+		err = DbSchema.Db().Create(modelObject).Error
+	} else {
+		err = DbSchema.Db().Save(modelObject).Error
+	}
+
+	if err != nil {
+		logErrorf("Query ERROR: %s", err)
+	}
+
+	return err
+}
+
+// Deletes model object. Models with DeletedAt field (embedding DbModel) are soft-deleted unless hard is true,
+// hard delete removes row physically. Returns error if database is not opened or object has no ID.
+func Delete[ModelT any](modelObject *ModelT, hard bool) error {
+	modelType := reflect.TypeFor[ModelT]()
+
+	if DbSchema.Db() == nil {
+		return errors.New("database is not opened")
+	}
+
+	if !DbSchema.HasModel(modelType) {
+		return fmt.Errorf("model %s is not registered with AddModel()", modelType.String())
+	}
+
+	if modelObject == nil || reflect.ValueOf(modelObject).Elem().FieldByName("ID").IsZero() {
+		return errors.New("modelObject is not saved (ID is not set)")
+	}
+
+	tx := DbSchema.Db()
+
+	if hard {
+		tx = tx.Unscoped()
+	}
+
+	if err := tx.Delete(modelObject).Error; err != nil {
+		logErrorf("Query ERROR: %s", err)
+		return err
+	}
+
+	return nil
+}
+
 // Saves object. Returns false and adds message to log if something goes wrong.
 func SaveObject(modelObject any) bool {
 	var t reflect.Type
@@ -156,7 +415,7 @@ func SaveObject(modelObject any) bool {
 
 	if err := DbSchema.Db().Save(modelObject).Error; err != nil {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Println("Query ERROR: " + err.Error())
+			logErrorf("Query ERROR: %s", err)
 		}
 
 		return false
@@ -195,7 +454,7 @@ func CreateObject(modelObject any) bool {
 	}
 
 	if err := DbSchema.Db().Create(modelObject).Error; err != nil {
-		log.Println("Query ERROR: " + err.Error())
+		logErrorf("Query ERROR: %s", err)
 		return false
 	}
 
@@ -216,7 +475,7 @@ func LoadOL[ModelT any]() (list []*ModelT) {
 
 	if err := gormTx.Find(&list).Error; err != nil {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Println("Query ERROR: " + err.Error())
+			logErrorf("Query ERROR: %s", err)
 		}
 	}
 
@@ -237,13 +496,77 @@ func CountOL[ModelT any]() (cnt int64) {
 
 	if err := gormTx.Count(&cnt).Error; err != nil {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Println("Query ERROR: " + err.Error())
+			logErrorf("Query ERROR: %s", err)
 		}
 	}
 
 	return cnt
 }
 
+// Default values of default_page_size and max_page_size settings
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Loads single page of model (O)bjects using prepared gorm TX - PreQuery(). page is 1-based.
+// pageSize is limited with max_page_size setting, default_page_size is used if it is not positive.
+// Returns objects list and total count of objects matching query (to calculate pages count).
+// if gorm TX was not prepared, empty one is created (paginating all model objects)
+func Paginate[ModelT any](page, pageSize int) (list []*ModelT, total int64) {
+	list = []*ModelT{} //empty list by default
+	defer func() { gormTx = nil }()
+
+	if gormTx == nil {
+		if gormTx = PreQuery[ModelT](); gormTx == nil {
+			return
+		}
+	}
+
+	page, pageSize = normalizePage(page, pageSize)
+
+	// new session to use same conditions for both count and select queries
+	query := gormTx.Session(&gorm.Session{})
+
+	if err := query.Count(&total).Error; err != nil {
+		logErrorf("Query ERROR: %s", err)
+		return
+	}
+
+	if err := query.Offset((page - 1) * pageSize).Limit(pageSize).Find(&list).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logErrorf("Query ERROR: %s", err)
+		}
+	}
+
+	return list, total
+}
+
+// Sets defaults for not positive page number and page size, limits page size with max_page_size setting.
+func normalizePage(page, pageSize int) (int, int) {
+	defaultPageSize, maxPageSize := DefaultPageSize, MaxPageSize
+
+	if DbSchema.settings != nil {
+		if DbSchema.settings.DefaultPageSize > 0 {
+			defaultPageSize = DbSchema.settings.DefaultPageSize
+		}
+
+		if DbSchema.settings.MaxPageSize > 0 {
+			maxPageSize = DbSchema.settings.MaxPageSize
+		}
+	}
+
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+
+	return page, min(pageSize, maxPageSize)
+}
+
 // Just a simple wrapper
 func Transaction(trxF func() error) {
 	DbSchema.Db().Transaction(func(tx *gorm.DB) error {
@@ -259,7 +582,7 @@ func checkSchemaModelType(t reflect.Type) bool {
 	}
 
 	if !DbSchema.HasModel(t) {
-		log.Printf("ERROR[checkSchemaModelType]: unknown model '%s'\n", t.String())
+		logErrorf("checkSchemaModelType(): unknown model '%s'", t.String())
 		return false
 	}
 
@@ -0,0 +1,66 @@
+package goapp
+
+import (
+	"reflect"
+
+	gorm "gorm.io/gorm"
+)
+
+// Modifies query built by LoadList().
+type QueryOption func(tx *gorm.DB) *gorm.DB
+
+// Sets query ORDER BY clause, like "created_at desc". Can be used several times.
+func WithOrder(order string) QueryOption {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Order(order)
+	}
+}
+
+// Limits number of loaded rows.
+func WithLimit(limit int) QueryOption {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Limit(limit)
+	}
+}
+
+// Skips first offset rows.
+func WithOffset(offset int) QueryOption {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Offset(offset)
+	}
+}
+
+// Adds WHERE condition, like WithWhere("name = ?", name). Conditions are joined with AND.
+func WithWhere(query any, args ...any) QueryOption {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where(query, args...)
+	}
+}
+
+// Loads all model objects matching options. Returns empty slice (not nil) if nothing was found.
+// Panics if ModelT was not registered with AddModel() or database is not opened.
+func LoadList[ModelT any](opts ...QueryOption) []ModelT {
+	modelType := reflect.TypeFor[ModelT]()
+
+	if !DbSchema.HasModel(modelType) {
+		logPanicf("LoadList(): model %s is not registered with AddModel()", modelType.String())
+	}
+
+	if DbSchema.Db() == nil {
+		logPanicf("LoadList(): database is not opened")
+	}
+
+	list := []ModelT{}
+	tx := DbSchema.Db().Model(new(ModelT))
+
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+
+	if err := tx.Find(&list).Error; err != nil {
+		logErrorf("Query ERROR: %s", err)
+		return []ModelT{}
+	}
+
+	return list
+}
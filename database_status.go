@@ -0,0 +1,96 @@
+package goapp
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	gorm "gorm.io/gorm"
+)
+
+// Schema state of single registered model
+type dbModelStatus struct {
+	Model          string
+	Table          string
+	TableExists    bool
+	MissingColumns []string
+	MissingIndexes []string
+}
+
+func (status *dbModelStatus) isUpToDate() bool {
+	return status.TableExists && len(status.MissingColumns) == 0 && len(status.MissingIndexes) == 0
+}
+
+// Checks live database schema for every registered model without migrating anything.
+func (db_schema *dbSchemaType) schemaStatus() ([]*dbModelStatus, error) {
+	migrator := db_schema.db.Migrator()
+	list := make([]*dbModelStatus, 0, len(db_schema.modelMap))
+
+	for _, name := range slices.Sorted(maps.Keys(db_schema.modelMap)) {
+		modelObject := db_schema.modelMap[name]
+
+		stmt := &gorm.Statement{DB: db_schema.db}
+		if err := stmt.Parse(modelObject); err != nil {
+			return nil, fmt.Errorf("unable to parse model %s: %w", name, err)
+		}
+
+		status := &dbModelStatus{
+			Model:       name,
+			Table:       stmt.Schema.Table,
+			TableExists: migrator.HasTable(modelObject),
+		}
+
+		if status.TableExists {
+			for _, field := range stmt.Schema.Fields {
+				if field.DBName != "" && !migrator.HasColumn(modelObject, field.DBName) {
+					status.MissingColumns = append(status.MissingColumns, field.DBName)
+				}
+			}
+
+			for _, index := range stmt.Schema.ParseIndexes() {
+				if !migrator.HasIndex(modelObject, index.Name) {
+					status.MissingIndexes = append(status.MissingIndexes, index.Name)
+				}
+			}
+
+			slices.Sort(status.MissingIndexes)
+		}
+
+		list = append(list, status)
+	}
+
+	return list, nil
+}
+
+// Prints schema status report. Returns number of models requiring migration.
+func printDbSchemaStatus(list []*dbModelStatus) int {
+	pending := 0
+
+	for _, status := range list {
+		switch {
+		case !status.TableExists:
+			fmt.Printf("%s (%s): table does not exist\n", status.Model, status.Table)
+
+		case status.isUpToDate():
+			fmt.Printf("%s (%s): up to date\n", status.Model, status.Table)
+
+		default:
+			fmt.Printf("%s (%s): pending changes\n", status.Model, status.Table)
+
+			if len(status.MissingColumns) > 0 {
+				fmt.Printf("  missing columns: %s\n", strings.Join(status.MissingColumns, ", "))
+			}
+
+			if len(status.MissingIndexes) > 0 {
+				fmt.Printf("  missing indexes: %s\n", strings.Join(status.MissingIndexes, ", "))
+			}
+		}
+
+		if !status.isUpToDate() {
+			pending++
+		}
+	}
+
+	return pending
+}
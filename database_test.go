@@ -0,0 +1,313 @@
+package goapp
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	gorm "gorm.io/gorm"
+)
+
+type testValidModel struct {
+	BaseModel
+
+	Name string
+}
+
+type testSoftDeleteModel struct {
+	DbModel
+
+	Name string
+}
+
+type testNoDbModel struct {
+	ID   int64
+	Name string
+}
+
+type testUnsupportedFieldModel struct {
+	BaseModel
+
+	Channel chan int
+}
+
+type testBadRelationModel struct {
+	BaseModel
+
+	Items []testValidModel `gorm:"foreignKey:NoSuchField"`
+}
+
+func TestAddModel(t *testing.T) {
+	DbSchema.AddModel(reflect.TypeFor[testValidModel]())
+
+	if !DbSchema.HasModel(reflect.TypeFor[testValidModel]()) {
+		t.Error("valid model was not added")
+	}
+}
+
+func TestAddModelMalformed(t *testing.T) {
+	malformed := map[string]reflect.Type{
+		"not a struct":      reflect.TypeFor[int](),
+		"no DbModel":        reflect.TypeFor[testNoDbModel](),
+		"anonymous struct":  reflect.TypeFor[struct{ BaseModel }](),
+		"unsupported field": reflect.TypeFor[testUnsupportedFieldModel](),
+		"bad relation":      reflect.TypeFor[testBadRelationModel](),
+	}
+
+	for name, modelType := range malformed {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("AddModel(%s) did not panic", modelType.String())
+				}
+			}()
+
+			DbSchema.AddModel(modelType)
+		})
+
+		if DbSchema.HasModel(modelType) {
+			t.Errorf("malformed model %s was added", modelType.String())
+		}
+	}
+}
+
+func TestTransactionRollback(t *testing.T) {
+	t.Chdir(t.TempDir()) // database file is created in working directory
+
+	DbSchema.AddModel(reflect.TypeFor[testValidModel]())
+
+	if err := DbSchema.Open(false); err != nil {
+		t.Fatal(err)
+	}
+	defer DbSchema.Close()
+
+	rollbackErr := errors.New("rollback")
+
+	err := DbSchema.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&testValidModel{Name: "first"}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(&testValidModel{Name: "second"}).Error; err != nil {
+			return err
+		}
+
+		return rollbackErr
+	})
+
+	if !errors.Is(err, rollbackErr) {
+		t.Fatalf("expected rollback error, got %v", err)
+	}
+
+	var count int64
+	if err := DbSchema.Db().Model(&testValidModel{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 0 {
+		t.Errorf("rolled back transaction left %d rows", count)
+	}
+}
+
+func TestTransactionNotOpened(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Transaction() did not panic with database not opened")
+		}
+	}()
+
+	DbSchema.Transaction(func(tx *gorm.DB) error { return nil })
+}
+
+func TestDelete(t *testing.T) {
+	t.Chdir(t.TempDir()) // database file is created in working directory
+
+	DbSchema.AddModel(reflect.TypeFor[testSoftDeleteModel]())
+
+	if err := DbSchema.Open(false); err != nil {
+		t.Fatal(err)
+	}
+	defer DbSchema.Close()
+
+	soft := &testSoftDeleteModel{Name: "soft"}
+	hard := &testSoftDeleteModel{Name: "hard"}
+
+	for _, modelObject := range []*testSoftDeleteModel{soft, hard} {
+		if err := Save(modelObject); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := Delete(soft, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Delete(hard, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if Load[testSoftDeleteModel](soft.ID) != nil {
+		t.Error("soft-deleted object was loaded")
+	}
+
+	var rows []testSoftDeleteModel
+	if err := DbSchema.Db().Unscoped().Find(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 1 || rows[0].ID != soft.ID || !rows[0].DeletedAt.Valid {
+		t.Errorf("expected only soft-deleted row to stay in table, got %+v", rows)
+	}
+}
+
+type testUUIDModel struct {
+	DbModelUUID
+
+	Name string
+}
+
+func TestUUIDModel(t *testing.T) {
+	t.Chdir(t.TempDir()) // database file is created in working directory
+
+	AddModel[testUUIDModel]()
+
+	if err := DbSchema.Open(false); err != nil {
+		t.Fatal(err)
+	}
+	defer DbSchema.Close()
+
+	modelObject := &testUUIDModel{Name: "first"}
+
+	if err := Save(modelObject); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(modelObject.ID) != 36 {
+		t.Fatalf("expected generated UUID, got '%s'", modelObject.ID)
+	}
+
+	loaded := Load[testUUIDModel](modelObject.ID)
+	if loaded == nil || loaded.Name != "first" {
+		t.Fatalf("object was not loaded by UUID: %+v", loaded)
+	}
+
+	loaded.Name = "updated"
+	if err := Save(loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Delete(loaded, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if Load[testUUIDModel](modelObject.ID) != nil {
+		t.Error("deleted object was loaded")
+	}
+}
+
+func TestWithLockRenewed(t *testing.T) {
+	t.Chdir(t.TempDir()) // database file is created in working directory
+
+	if err := DbSchema.Open(false); err != nil {
+		t.Fatal(err)
+	}
+	defer DbSchema.Close()
+
+	app := NewAppBase(&testShutdownSettings{})
+	ttl := 100 * time.Millisecond
+
+	err := app.WithLock("job", ttl, func() error {
+		// fn outlives ttl, lock should be renewed meanwhile
+		time.Sleep(3 * ttl)
+
+		return app.WithLock("job", ttl, func() error {
+			t.Error("lock acquired twice")
+			return nil
+		})
+	})
+
+	if !errors.Is(err, ErrLockNotAcquired) {
+		t.Fatalf("expected ErrLockNotAcquired, got %v", err)
+	}
+
+	// released after fn returns
+	if err := app.WithLock("job", ttl, func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type testStringIdModel struct {
+	schemaModel
+
+	Code string `gorm:"primaryKey;size:50"`
+	Name string
+}
+
+func TestStringIdModelHelpers(t *testing.T) {
+	t.Chdir(t.TempDir()) // database file is created in working directory
+
+	AddModel[testStringIdModel]()
+
+	if err := DbSchema.Open(false); err != nil {
+		t.Fatal(err)
+	}
+	defer DbSchema.Close()
+
+	if err := DbSchema.Db().Create(&testStringIdModel{Code: "eur", Name: "Euro"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded := LoadO[testStringIdModel]("eur"); loaded == nil || loaded.Name != "Euro" {
+		t.Errorf("LoadO() did not load object by string id: %+v", loaded)
+	}
+
+	if loaded := LoadO[testStringIdModel]("usd"); loaded != nil {
+		t.Errorf("LoadO() loaded missing object: %+v", loaded)
+	}
+
+	if loaded, err := LoadWith[testStringIdModel]("eur"); err != nil || loaded.Name != "Euro" {
+		t.Errorf("LoadWith() did not load object by string id: %+v, %v", loaded, err)
+	}
+
+	if loaded := LoadOMust[testStringIdModel]("eur"); loaded.Code != "eur" {
+		t.Errorf("LoadOMust() loaded wrong object: %+v", loaded)
+	}
+
+	if created := LoadOrCreateO[testStringIdModel](""); created == nil || created.Code != "" {
+		t.Errorf("LoadOrCreateO() did not create empty object: %+v", created)
+	}
+
+	if loaded := LoadOrCreateO[testStringIdModel]("eur"); loaded == nil || loaded.Name != "Euro" {
+		t.Errorf("LoadOrCreateO() did not load object by string id: %+v", loaded)
+	}
+}
+
+func TestReadOnlyDatabaseRejectsRawWrites(t *testing.T) {
+	t.Chdir(t.TempDir()) // database file is created in working directory
+
+	AddModel[testValidModel]()
+
+	if err := DbSchema.Open(false); err != nil {
+		t.Fatal(err)
+	}
+
+	DbSchema.Close()
+
+	previousSettings := DbSchema.settings
+	DbSchema.settings = &AppSettingsBase{DatabaseReadOnly: true}
+	defer func() { DbSchema.settings = previousSettings }()
+
+	if err := DbSchema.Open(false); err != nil {
+		t.Fatal(err)
+	}
+	defer DbSchema.Close()
+
+	if err := Save(&testValidModel{Name: "x"}); err == nil {
+		t.Error("model saved in read-only mode")
+	}
+
+	if err := DbSchema.Db().Exec("INSERT INTO test_valid_model (name) VALUES ('x')").Error; err == nil {
+		t.Error("raw SQL write succeeded in read-only mode")
+	}
+}
@@ -0,0 +1,52 @@
+package goapp
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// Calls fn until it succeeds or attempts are exhausted. Delay between attempts starts from backoff
+// and doubles after every failed attempt (up to 50% random jitter is added to each delay).
+// Returns last fn error, or ctx error if ctx was cancelled before success.
+func Retry(ctx context.Context, attempts int, backoff time.Duration, fn func() error) error {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	delay := backoff
+
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := fn()
+
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= attempts {
+			return err
+		}
+
+		wait := delay
+		if delay > 0 {
+			wait += rand.N(delay/2 + 1)
+		}
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w (last error: %s)", ctx.Err(), err.Error())
+
+		case <-timer.C:
+		}
+
+		delay *= 2
+	}
+}
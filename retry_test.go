@@ -0,0 +1,54 @@
+package goapp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	calls := 0
+
+	err := Retry(context.Background(), 5, time.Millisecond, func() error {
+		calls++
+
+		if calls < 3 {
+			return errors.New("not yet")
+		}
+
+		return nil
+	})
+
+	if err != nil || calls != 3 {
+		t.Errorf("expected success after 3 calls, got %d calls and error %v", calls, err)
+	}
+
+	calls = 0
+	lastErr := errors.New("always")
+
+	err = Retry(context.Background(), 3, time.Millisecond, func() error {
+		calls++
+		return lastErr
+	})
+
+	if !errors.Is(err, lastErr) || calls != 3 {
+		t.Errorf("expected last error after 3 calls, got %d calls and error %v", calls, err)
+	}
+}
+
+func TestRetryCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+
+	err := Retry(ctx, 10, time.Hour, func() error {
+		calls++
+		cancel()
+
+		return errors.New("failed")
+	})
+
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("expected context.Canceled after 1 call, got %d calls and error %v", calls, err)
+	}
+}
@@ -0,0 +1,191 @@
+package goapp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/mitoteam/mttools"
+)
+
+// systemd service unit. Type=notify: app reports readiness and stopping with sd_notify.
+const systemdUnitTemplate = `[Unit]
+Description={{ .Name }}
+After=network.target
+StartLimitIntervalSec=60
+StartLimitBurst=5
+
+[Service]
+RestartSec=2s
+Type=notify
+User={{ .User }}
+Group={{ .Group }}
+WorkingDirectory={{ .WorkingDir }}
+ExecStart={{ .Executable }} run
+Restart=always
+{{- if .WatchdogSec }}
+WatchdogSec={{ .WatchdogSec }}
+{{- end }}
+
+[Install]
+WantedBy=multi-user.target
+`
+
+// systemd service unit data
+type systemdUnit struct {
+	mttools.ServiceData
+
+	WatchdogSec uint // 0 = no watchdog
+}
+
+// Prepares service unit data from settings for current executable and working directory.
+func (app *AppBase) systemdServiceData() (*systemdUnit, error) {
+	unit := &systemdUnit{
+		ServiceData: mttools.ServiceData{
+			Name:      app.baseSettings.ServiceName,
+			User:      app.baseSettings.ServiceUser,
+			Group:     app.baseSettings.ServiceGroup,
+			Autostart: app.serviceAutostart,
+		},
+		WatchdogSec: app.baseSettings.ServiceWatchdogSec,
+	}
+
+	var err error
+
+	if unit.Executable, err = os.Executable(); err != nil {
+		return nil, err
+	}
+
+	if unit.WorkingDir, err = os.Getwd(); err != nil {
+		return nil, err
+	}
+
+	return unit, nil
+}
+
+func renderSystemdUnit(w io.Writer, unit *systemdUnit) error {
+	t, err := template.New("service").Parse(systemdUnitTemplate)
+	if err != nil {
+		return err
+	}
+
+	return t.Execute(w, unit)
+}
+
+// Creates service unit file, reloads systemd and enables service autostart (if requested).
+func installSystemdService(unit *systemdUnit) error {
+	unitFilename := filepath.Join(mttools.SystemdServiceDirPath, unit.Name+".service")
+
+	if mttools.IsFileExists(unitFilename) {
+		return fmt.Errorf("File %s already exists. Use 'uninstall' command or remove file manually.", unitFilename)
+	}
+
+	file, err := os.OpenFile(unitFilename, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+
+	if err := renderSystemdUnit(file, unit); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	log.Printf("File %s created.\n", unitFilename)
+
+	log.Println("Reloading systemctl daemon")
+	if out, err := exec.Command("systemctl", "daemon-reload").Output(); err != nil {
+		return err
+	} else {
+		log.Println(string(out))
+	}
+
+	if unit.Autostart {
+		log.Printf("Enabling '%s' service autostart.\n", unit.Name)
+
+		if out, err := exec.Command("systemctl", "enable", unit.Name).Output(); err != nil {
+			return err
+		} else {
+			log.Println(string(out))
+		}
+	}
+
+	return nil
+}
+
+// Sends state notification to systemd (sd_notify protocol). Does nothing if app is not
+// started by systemd with Type=notify (NOTIFY_SOCKET environment variable is not set).
+func systemdNotify(state string) error {
+	socketPath := os.Getenv("NOTIFY_SOCKET")
+
+	if socketPath == "" {
+		return nil
+	}
+
+	// abstract namespace socket
+	if socketPath[0] == '@' {
+		socketPath = "\x00" + socketPath[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(state))
+
+	return err
+}
+
+// Sends WATCHDOG=1 notifications to systemd while app is healthy if watchdog is enabled for
+// service (WATCHDOG_USEC environment variable is set). systemd restarts service if notifications stop.
+func (app *AppBase) startSystemdWatchdog() {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return // watchdog is not enabled
+	}
+
+	// watchdog is meant for other process
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return
+	}
+
+	// notify twice per watchdog interval as recommended by sd_watchdog_enabled(3)
+	interval := time.Duration(usec) * time.Microsecond / 2
+
+	logInfof("systemd watchdog enabled (interval %s)", interval)
+
+	app.Go(func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-ticker.C:
+				if err := app.checkHealth(); err != nil {
+					logWarnf("Health check failed, systemd watchdog is not notified: %s", err)
+					continue
+				}
+
+				if err := systemdNotify("WATCHDOG=1"); err != nil {
+					logErrorf("systemd notify error: %s", err)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package goapp
+
+import (
+	"crypto/tls"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// Sets up server TLS config from settings. TLSConfig is left nil if server should use plain HTTP.
+// Automatic certificates (Let's Encrypt) are used if tls_autocert_domains are set,
+// certificate files are used otherwise.
+func (app *AppBase) configureServerTls(httpSrv *http.Server) error {
+	settings := app.baseSettings
+
+	if len(settings.TlsAutocertDomains) > 0 {
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(settings.TlsAutocertDomains...),
+			Cache:      autocert.DirCache(settings.TlsAutocertCacheDir),
+		}
+
+		// certificates are obtained with TLS-ALPN-01 challenge, so server should be reachable at 443 port
+		httpSrv.TLSConfig = manager.TLSConfig()
+		logInfof("Automatic TLS certificates enabled for: %v", settings.TlsAutocertDomains)
+
+		return nil
+	}
+
+	if settings.TlsCertFile != "" && settings.TlsKeyFile != "" {
+		certificateHolder, err := newTlsCertificateHolder(settings.TlsCertFile, settings.TlsKeyFile)
+		if err != nil {
+			return err
+		}
+
+		certificateHolder.reloadOnSighup(app.BaseContext.Done())
+		httpSrv.TLSConfig = &tls.Config{GetCertificate: certificateHolder.getCertificate}
+	}
+
+	return nil
+}
+
+// Holds TLS certificate loaded from files. Certificate can be reloaded without server restart.
+type tlsCertificateHolder struct {
+	certFile string
+	keyFile  string
+
+	certificate atomic.Pointer[tls.Certificate]
+}
+
+func newTlsCertificateHolder(certFile, keyFile string) (*tlsCertificateHolder, error) {
+	holder := &tlsCertificateHolder{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+
+	if err := holder.load(); err != nil {
+		return nil, err
+	}
+
+	return holder, nil
+}
+
+// (Re)loads certificate from files. Previous certificate is kept if loading fails.
+func (holder *tlsCertificateHolder) load() error {
+	certificate, err := tls.LoadX509KeyPair(holder.certFile, holder.keyFile)
+	if err != nil {
+		return err
+	}
+
+	holder.certificate.Store(&certificate)
+
+	return nil
+}
+
+// tls.Config.GetCertificate callback
+func (holder *tlsCertificateHolder) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return holder.certificate.Load(), nil
+}
+
+// Reloads certificate every time SIGHUP is received until done channel is closed.
+func (holder *tlsCertificateHolder) reloadOnSighup(done <-chan struct{}) {
+	hup_channel := make(chan os.Signal, 1)
+	signal.Notify(hup_channel, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(hup_channel)
+
+		for {
+			select {
+			case <-done:
+				return
+
+			case <-hup_channel:
+				if err := holder.load(); err != nil {
+					logErrorf("TLS certificate reloading ERROR (previous one is kept): %s", err)
+				} else {
+					logInfof("TLS certificate reloaded from %s", holder.certFile)
+				}
+			}
+		}
+	}()
+}
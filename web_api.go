@@ -1,16 +1,21 @@
 package goapp
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 type (
 	ApiRequest struct {
+		body    []byte
 		inData  map[string]interface{}
 		outData map[string]interface{}
 		session sessions.Session
@@ -41,6 +46,7 @@ func newApiRequest(c *gin.Context) (*ApiRequest, error) {
 		return nil, err
 	}
 	//log.Println(string(body))
+	r.body = body
 
 	if json.Valid(body) {
 		if err := json.Unmarshal(body, &r.inData); err != nil {
@@ -80,6 +86,37 @@ func (r *ApiRequest) GetInDataInt(name string, default_value int) int {
 	return default_value
 }
 
+// Decodes JSON request body to obj and validates it with `binding:"..."` tags (same as gin's
+// ShouldBindJSON). On failure writes 400 reply (with "errors" object if validation failed, see
+// BindAndValidate()) and returns false, handler should just return nil then:
+//
+//	if !r.BindJSON(&req) {
+//		return nil
+//	}
+func (r *ApiRequest) BindJSON(obj any) bool {
+	err := r.bindBody(obj, false)
+	if err == nil {
+		return true
+	}
+
+	abortWithBindError(r.context, http.StatusBadRequest, err, obj)
+
+	return false
+}
+
+// Decodes JSON body to obj and validates it. Empty body leaves obj as is (but still validated) if allowEmpty is set.
+func (r *ApiRequest) bindBody(obj any, allowEmpty bool) error {
+	if len(bytes.TrimSpace(r.body)) == 0 {
+		if !allowEmpty {
+			return errors.New("request body is empty")
+		}
+
+		return binding.Validator.ValidateStruct(obj)
+	}
+
+	return binding.JSON.BindBody(r.body, obj)
+}
+
 func (r *ApiRequest) GetOutData(name string) string {
 	if value, ok := r.outData[name]; ok {
 		return value.(string)
@@ -105,6 +142,11 @@ func (r *ApiRequest) SetErrorStatus(message string) {
 	r.setStatus("error", message)
 }
 
+// Returns gin context of request (to read values set by middleware with ContextKey or SetUser()).
+func (r *ApiRequest) GinContext() *gin.Context {
+	return r.context
+}
+
 func (r *ApiRequest) Session() sessions.Session {
 	return r.session
 }
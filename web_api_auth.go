@@ -0,0 +1,33 @@
+package goapp
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Authenticates API requests with ApiAuthF. Identity is stored with SetUser(), so handlers get it
+// with ApiRequest.Identity() or GetUser(). Request is rejected with 401 if ApiAuthF returns error.
+func (app *AppBase) webApiAuthMiddleware(c *gin.Context) {
+	identity, err := app.ApiAuthF(c)
+
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+
+		return
+	}
+
+	SetUser(c, identity)
+
+	c.Next()
+}
+
+// Returns identity returned by ApiAuthF (nil if ApiAuthF is not set).
+func (r *ApiRequest) Identity() any {
+	identity, _ := GetUser[any](r.context)
+
+	return identity
+}
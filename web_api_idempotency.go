@@ -0,0 +1,219 @@
+package goapp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// expired idempotency records are removed with this interval
+const idempotencyCleanupInterval = time.Minute
+
+// Stored API response for idempotency key. Record is inserted as pending before handler is called,
+// so primary key lets only one of concurrent requests with the same key run the handler.
+type dbIdempotencyRecord struct {
+	Key         string `gorm:"column:idempotency_key;primaryKey;size:190"` // hash of caller scope and header value
+	Path        string
+	Pending     bool // handler is still running
+	Status      int
+	ContentType string
+	Body        []byte
+	ExpiresAt   time.Time `gorm:"index"`
+}
+
+func (dbIdempotencyRecord) TableName() string {
+	return "goapp_idempotency"
+}
+
+// idempotency table is migrated on first use (not in Open() to not add it to apps not using this
+// feature) once per opened database
+var (
+	idempotencyTableMu sync.Mutex
+	idempotencyTableDb *gorm.DB // database table was migrated in
+)
+
+func ensureIdempotencyTable(db *gorm.DB) error {
+	idempotencyTableMu.Lock()
+	defer idempotencyTableMu.Unlock()
+
+	if idempotencyTableDb == db {
+		return nil
+	}
+
+	if err := db.AutoMigrate(&dbIdempotencyRecord{}); err != nil {
+		return err
+	}
+
+	idempotencyTableDb = db
+
+	return nil
+}
+
+// Middleware for POST API requests with Idempotency-Key header. First request with a key is processed
+// and its response is stored in database. Retries with same key get stored response without
+// handler being called again, retries sent while first request is still running get 409.
+// Keys are scoped by caller (ApiAuthF identity, Authorization header or client IP), so one client
+// can not get responses stored for another one. Keys expire after webapi_idempotency_ttl seconds.
+func (app *AppBase) webApiIdempotencyMiddleware(c *gin.Context) {
+	key := c.GetHeader(idempotencyKeyHeader)
+	db := DbSchema.Db()
+
+	if c.Request.Method != http.MethodPost || key == "" || db == nil {
+		c.Next()
+		return
+	}
+
+	if err := ensureIdempotencyTable(db); err != nil {
+		logErrorf("Idempotency table migration ERROR: %s", err)
+		http.Error(c.Writer, "idempotency storage is not available", http.StatusInternalServerError)
+		c.Abort()
+		return
+	}
+
+	record := dbIdempotencyRecord{
+		Key:       idempotencyScopedKey(c, key),
+		Path:      c.Request.URL.Path,
+		Pending:   true,
+		ExpiresAt: time.Now().Add(time.Duration(app.baseSettings.WebApiIdempotencyTtl) * time.Second),
+	}
+
+	for attempt := 0; ; attempt++ {
+		// nothing is inserted if there is record for the key already
+		result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+
+		if result.Error != nil {
+			logErrorf("Idempotency record saving ERROR: %s", result.Error)
+			http.Error(c.Writer, "idempotency storage is not available", http.StatusInternalServerError)
+			c.Abort()
+			return
+		}
+
+		if result.RowsAffected > 0 {
+			break // this request owns the key
+		}
+
+		var stored dbIdempotencyRecord
+
+		if db.Where("idempotency_key = ?", record.Key).Limit(1).Find(&stored).RowsAffected == 0 {
+			if attempt == 0 {
+				continue // record was removed meanwhile
+			}
+
+			http.Error(c.Writer, "idempotency storage is not available", http.StatusInternalServerError)
+			c.Abort()
+			return
+		}
+
+		// expired record is not removed by cleanup yet
+		if stored.ExpiresAt.Before(time.Now()) && attempt == 0 {
+			db.Where("idempotency_key = ? AND expires_at < ?", record.Key, time.Now()).Delete(&dbIdempotencyRecord{})
+			continue
+		}
+
+		writeIdempotencyReplay(c, &stored)
+		return
+	}
+
+	completed := false
+
+	// handler failed (or panicked): remove pending record, so request can be retried
+	defer func() {
+		if !completed {
+			db.Where("idempotency_key = ? AND pending = ?", record.Key, true).Delete(&dbIdempotencyRecord{})
+		}
+	}()
+
+	writer := &webCacheWriter{ResponseWriter: c.Writer}
+	c.Writer = writer
+
+	c.Next()
+
+	// do not store server errors, so request can be retried
+	if writer.Status() >= http.StatusInternalServerError {
+		return
+	}
+
+	err := db.Model(&dbIdempotencyRecord{}).Where("idempotency_key = ?", record.Key).Updates(map[string]any{
+		"pending":      false,
+		"status":       writer.Status(),
+		"content_type": writer.Header().Get("Content-Type"),
+		"body":         writer.body.Bytes(),
+	}).Error
+
+	if err != nil {
+		logErrorf("Idempotency record saving ERROR: %s", err)
+		return
+	}
+
+	completed = true
+}
+
+// Replies to retried request with stored response.
+func writeIdempotencyReplay(c *gin.Context, record *dbIdempotencyRecord) {
+	switch {
+	case record.Path != c.Request.URL.Path:
+		http.Error(c.Writer, idempotencyKeyHeader+" was used for another request path", http.StatusUnprocessableEntity)
+
+	case record.Pending:
+		http.Error(c.Writer, "request with this "+idempotencyKeyHeader+" is still being processed", http.StatusConflict)
+
+	default:
+		c.Header("Idempotent-Replayed", "true")
+		c.Data(record.Status, record.ContentType, record.Body)
+	}
+
+	c.Abort()
+}
+
+// Binds key to caller: authenticated identity (see ApiAuthF and SetUser()), Authorization header or client IP.
+func idempotencyScopedKey(c *gin.Context, key string) string {
+	var scope string
+
+	if user, ok := GetUser[any](c); ok && user != nil {
+		scope = fmt.Sprintf("user:%v", user)
+	} else if authorization := c.GetHeader("Authorization"); authorization != "" {
+		scope = "auth:" + authorization
+	} else {
+		scope = "ip:" + c.RemoteIP()
+	}
+
+	hash := sha256.Sum256([]byte(scope + "\n" + key))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// Removes expired idempotency records periodically. Started by web router if idempotency is enabled.
+func (app *AppBase) idempotencyCleanupLoop(ctx context.Context) {
+	ticker := time.NewTicker(idempotencyCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			db := DbSchema.Db()
+
+			idempotencyTableMu.Lock()
+			ready := db != nil && idempotencyTableDb == db
+			idempotencyTableMu.Unlock()
+
+			if ready {
+				if err := db.Where("expires_at < ?", time.Now()).Delete(&dbIdempotencyRecord{}).Error; err != nil {
+					logErrorf("Idempotency records cleanup ERROR: %s", err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package goapp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIdempotencyConcurrentRetries(t *testing.T) {
+	t.Chdir(t.TempDir()) // database file is created in working directory
+
+	if err := DbSchema.Open(false); err != nil {
+		t.Fatal(err)
+	}
+	defer DbSchema.Close()
+
+	app := NewAppBase(&testShutdownSettings{})
+	app.baseSettings.WebApiIdempotencyTtl = 60
+
+	var calls atomic.Int32
+
+	engine := gin.New()
+	engine.POST("/api/pay", app.webApiIdempotencyMiddleware, func(c *gin.Context) {
+		calls.Add(1)
+		time.Sleep(100 * time.Millisecond)
+		c.String(http.StatusOK, "paid")
+	})
+
+	send := func(authorization string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/api/pay", nil)
+		request.Header.Set(idempotencyKeyHeader, "payment-1")
+		request.Header.Set("Authorization", authorization)
+
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	var wg sync.WaitGroup
+	var okCount, conflictCount atomic.Int32
+
+	for range 5 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			switch send("Bearer alice").Code {
+			case http.StatusOK:
+				okCount.Add(1)
+			case http.StatusConflict:
+				conflictCount.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if calls.Load() != 1 || okCount.Load() != 1 || conflictCount.Load() != 4 {
+		t.Fatalf("expected single handler call, got %d calls, %d OK and %d conflict replies",
+			calls.Load(), okCount.Load(), conflictCount.Load())
+	}
+
+	if r := send("Bearer alice"); r.Code != http.StatusOK || r.Header().Get("Idempotent-Replayed") != "true" || r.Body.String() != "paid" {
+		t.Errorf("stored response was not replayed: %d %q", r.Code, r.Body.String())
+	}
+
+	// same key from another caller is a different request
+	if r := send("Bearer bob"); r.Header().Get("Idempotent-Replayed") != "" || calls.Load() != 2 {
+		t.Errorf("response stored for another caller was replayed")
+	}
+}
@@ -0,0 +1,82 @@
+package goapp
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// Single JSON schema violation reported to API client
+type ApiSchemaViolation struct {
+	Path    string `json:"path"`    // JSON pointer to invalid value in request body
+	Message string `json:"message"` // violation description
+}
+
+// Associates JSON schema with API route. Request body is validated against schema before
+// handler is called. Requests failing validation get 400 reply listing schema violations.
+// Panics if schema can not be compiled.
+func (app *AppBase) ApiSchema(path string, schemaJson string) *AppBase {
+	doc, err := jsonschema.UnmarshalJSON(strings.NewReader(schemaJson))
+	if err != nil {
+		logPanicf("API path '%s' schema is not valid JSON: %s", path, err.Error())
+	}
+
+	url := "api://" + strings.TrimPrefix(path, "/") + ".schema.json"
+
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(url, doc); err != nil {
+		logPanicf("API path '%s' schema error: %s", path, err.Error())
+	}
+
+	schema, err := compiler.Compile(url)
+	if err != nil {
+		logPanicf("API path '%s' schema compilation error: %s", path, err.Error())
+	}
+
+	app.webApiSchemaList[path] = schema
+
+	return app //for method chaining
+}
+
+// Validates request body against schema. Returns nil if body is valid.
+func validateApiRequestSchema(schema *jsonschema.Schema, body []byte) (violations []ApiSchemaViolation) {
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return []ApiSchemaViolation{{Path: "", Message: "invalid JSON: " + err.Error()}}
+	}
+
+	err = schema.Validate(instance)
+	if err == nil {
+		return nil
+	}
+
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return []ApiSchemaViolation{{Path: "", Message: err.Error()}}
+	}
+
+	for _, unit := range validationErr.BasicOutput().Errors {
+		if unit.Error != nil {
+			violations = append(violations, ApiSchemaViolation{Path: unit.InstanceLocation, Message: unit.Error.String()})
+		}
+	}
+
+	if len(violations) == 0 {
+		violations = append(violations, ApiSchemaViolation{Path: "", Message: validationErr.Error()})
+	}
+
+	return violations
+}
+
+// Writes 400 reply with schema violations list
+func writeApiSchemaViolations(c *gin.Context, violations []ApiSchemaViolation) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"status":     "error",
+		"message":    "request body does not match schema",
+		"violations": violations,
+	})
+}
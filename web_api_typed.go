@@ -0,0 +1,91 @@
+package goapp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// Error with HTTP status for ApiJson() handlers (like 404 for missing object or 403 for forbidden action).
+// Other errors are replied with 500 status.
+type ApiError struct {
+	Status  int
+	Message string
+}
+
+func (e *ApiError) Error() string {
+	return e.Message
+}
+
+// Creates error to be replied with given HTTP status by ApiJson() handlers.
+func NewApiError(status int, message string) error {
+	return &ApiError{Status: status, Message: message}
+}
+
+// key to get ApiRequest in ApiJson() handlers
+type apiRequestContextKey struct{}
+
+// Returns API request being handled from ctx passed to ApiJson() handler (to access session, identity
+// or gin context). Returns nil for other contexts.
+func ApiRequestFromContext(ctx context.Context) *ApiRequest {
+	r, _ := ctx.Value(apiRequestContextKey{}).(*ApiRequest)
+
+	return r
+}
+
+// Registers typed API handler. JSON request body is decoded to Req and validated with `binding:"..."`
+// tags (empty body means zero Req), fn result is replied as {"ok": true, "data": resp} envelope
+// regardless of WebApiUseEnvelope. Errors are replied as {"ok": false, "error": "..."} with 400 status
+// for invalid request (with "errors" object for validation errors, see BindAndValidate()), ApiError
+// status or 500 for other fn errors. ctx is request context, see ApiRequestFromContext().
+func ApiJson[Req, Resp any](app *AppBase, path string, fn func(ctx context.Context, req Req) (Resp, error)) *AppBase {
+	return app.ApiHandler(path, func(r *ApiRequest) error {
+		var req Req
+
+		if err := r.bindBody(&req, true); err != nil {
+			writeApiEnvelopeBindError(r.context, err, &req)
+			return nil
+		}
+
+		ctx := context.WithValue(r.context.Request.Context(), apiRequestContextKey{}, r)
+
+		resp, err := fn(ctx, req)
+		if err != nil {
+			status := http.StatusInternalServerError
+
+			var apiErr *ApiError
+			if errors.As(err, &apiErr) {
+				status = apiErr.Status
+			} else {
+				logErrorf("API Request error: %s", err)
+			}
+
+			r.context.AbortWithStatusJSON(status, apiEnvelope{Ok: false, Error: err.Error()})
+			return nil
+		}
+
+		r.context.AbortWithStatusJSON(http.StatusOK, apiEnvelope{Ok: true, Data: resp})
+		return nil
+	})
+}
+
+// Same as abortWithBindError() but in envelope format.
+func writeApiEnvelopeBindError(c *gin.Context, err error, obj any) {
+	var validationErrors validator.ValidationErrors
+
+	if errors.As(err, &validationErrors) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, apiEnvelope{
+			Ok:     false,
+			Error:  "validation failed",
+			Errors: validationErrorsMap(validationErrors, reflect.TypeOf(obj)),
+		})
+
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusBadRequest, apiEnvelope{Ok: false, Error: err.Error()})
+}
@@ -0,0 +1,251 @@
+package goapp
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Cache size limits: when one is exceeded expired entries are removed first, then arbitrary ones.
+const (
+	webCacheMaxEntries = 10_000
+	webCacheMaxBytes   = 64 << 20 // cached bodies total size
+)
+
+// expired entries are removed with this interval
+const webCacheSweepInterval = time.Minute
+
+// In-memory web responses cache
+type webResponseCache struct {
+	mu        sync.Mutex
+	entries   map[string]*webCacheEntry // key = method + path + sorted query
+	size      int                       // cached bodies total size
+	lastSweep time.Time
+}
+
+type webCacheEntry struct {
+	status  int
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+// Response writer copying written body to buffer
+type webCacheWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *webCacheWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *webCacheWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+func newWebResponseCache() *webResponseCache {
+	return &webResponseCache{
+		entries:   make(map[string]*webCacheEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+func (cache *webResponseCache) get(key string) *webCacheEntry {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	entry, ok := cache.entries[key]
+	if !ok {
+		return nil
+	}
+
+	if time.Now().After(entry.expires) {
+		cache.remove(key)
+		return nil
+	}
+
+	return entry
+}
+
+func (cache *webResponseCache) set(key string, entry *webCacheEntry) {
+	if len(entry.body) > webCacheMaxBytes/16 {
+		return // too big to take cache space from many other responses
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	now := time.Now()
+
+	if now.Sub(cache.lastSweep) > webCacheSweepInterval {
+		cache.sweep(now)
+	}
+
+	cache.remove(key)
+
+	if len(cache.entries) >= webCacheMaxEntries || cache.size+len(entry.body) > webCacheMaxBytes {
+		cache.sweep(now)
+	}
+
+	// map iteration order is random, so arbitrary entries are evicted
+	for existing := range cache.entries {
+		if len(cache.entries) < webCacheMaxEntries && cache.size+len(entry.body) <= webCacheMaxBytes {
+			break
+		}
+
+		cache.remove(existing)
+	}
+
+	cache.entries[key] = entry
+	cache.size += len(entry.body)
+}
+
+// Removes expired entries.
+func (cache *webResponseCache) sweep(now time.Time) {
+	for key, entry := range cache.entries {
+		if now.After(entry.expires) {
+			cache.remove(key)
+		}
+	}
+
+	cache.lastSweep = now
+}
+
+func (cache *webResponseCache) remove(key string) {
+	if entry, ok := cache.entries[key]; ok {
+		cache.size -= len(entry.body)
+		delete(cache.entries, key)
+	}
+}
+
+// Removes cached responses with paths starting with pathPrefix (all of them if pathPrefix is empty).
+func (cache *webResponseCache) purge(pathPrefix string) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	for key := range cache.entries {
+		//key is "METHOD /path?query"
+		if pathPrefix == "" || strings.HasPrefix(key[strings.Index(key, " ")+1:], pathPrefix) {
+			cache.remove(key)
+		}
+	}
+}
+
+// Removes cached responses with paths starting with pathPrefix (all of them if pathPrefix is empty).
+func (app *AppBase) PurgeWebCache(pathPrefix string) {
+	if app.webCache != nil {
+		app.webCache.purge(pathPrefix)
+	}
+}
+
+// Looks up cache TTL for request route in settings (route pattern first, request path then).
+func (app *AppBase) webCacheTtl(c *gin.Context) time.Duration {
+	seconds, ok := app.baseSettings.WebserverCacheRoutes[c.FullPath()]
+
+	if !ok {
+		seconds = app.baseSettings.WebserverCacheRoutes[c.Request.URL.Path]
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// Middleware serving GET and HEAD responses from cache for routes listed in settings.
+func (app *AppBase) webCacheMiddleware(c *gin.Context) {
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		c.Next()
+		return
+	}
+
+	ttl := app.webCacheTtl(c)
+	if ttl <= 0 {
+		c.Next()
+		return
+	}
+
+	key := webCacheKey(c.Request)
+	requestCacheControl := c.GetHeader("Cache-Control")
+
+	//client asks to bypass cache
+	if strings.Contains(requestCacheControl, "no-cache") || strings.Contains(requestCacheControl, "no-store") {
+		c.Next()
+		return
+	}
+
+	//cache key does not include caller, so responses for authenticated requests are never shared
+	if c.GetHeader("Authorization") != "" || app.hasSessionCookie(c) {
+		c.Next()
+		return
+	}
+
+	if entry := app.webCache.get(key); entry != nil {
+		for name, values := range entry.header {
+			c.Writer.Header()[name] = values
+		}
+
+		maxAge := int(time.Until(entry.expires).Seconds())
+		c.Header("Cache-Control", "public, max-age="+strconv.Itoa(maxAge))
+		c.Header("X-Cache", "HIT")
+
+		c.Data(entry.status, entry.header.Get("Content-Type"), entry.body)
+		c.Abort()
+		return
+	}
+
+	writer := &webCacheWriter{ResponseWriter: c.Writer}
+	c.Writer = writer
+	c.Header("X-Cache", "MISS")
+
+	c.Next()
+
+	if writer.Status() != http.StatusOK {
+		return
+	}
+
+	//handler does not allow response to be cached
+	responseCacheControl := writer.Header().Get("Cache-Control")
+	if strings.Contains(responseCacheControl, "no-store") || strings.Contains(responseCacheControl, "private") {
+		return
+	}
+
+	//response depends on request headers cache key does not include
+	if writer.Header().Get("Vary") != "" {
+		return
+	}
+
+	header := writer.Header().Clone()
+	header.Del("X-Cache")
+	header.Del("Set-Cookie") //never share cookies between clients
+
+	app.webCache.set(key, &webCacheEntry{
+		status:  writer.Status(),
+		header:  header,
+		body:    writer.body.Bytes(),
+		expires: time.Now().Add(ttl),
+	})
+}
+
+// Checks if request carries session cookie (see web router sessions setup).
+func (app *AppBase) hasSessionCookie(c *gin.Context) bool {
+	_, err := c.Request.Cookie(app.ExecutableName)
+
+	return err == nil
+}
+
+// Cache key is "METHOD /path?query" with query parameters sorted, so their order does not make new entries.
+func webCacheKey(request *http.Request) string {
+	key := request.Method + " " + request.URL.EscapedPath()
+
+	if query := request.URL.Query(); len(query) > 0 {
+		key += "?" + query.Encode()
+	}
+
+	return key
+}
@@ -0,0 +1,51 @@
+package goapp
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWebResponseCacheLimits(t *testing.T) {
+	cache := newWebResponseCache()
+
+	for i := range webCacheMaxEntries + 100 {
+		cache.set("GET /items?x="+strconv.Itoa(i), &webCacheEntry{
+			status:  200,
+			body:    []byte("item"),
+			expires: time.Now().Add(time.Minute),
+		})
+	}
+
+	if len(cache.entries) != webCacheMaxEntries {
+		t.Errorf("expected %d entries, got %d", webCacheMaxEntries, len(cache.entries))
+	}
+
+	if cache.size != webCacheMaxEntries*len("item") {
+		t.Errorf("wrong cached bodies size %d", cache.size)
+	}
+
+	// expired entries are removed first
+	cache.purge("")
+	cache.set("GET /old", &webCacheEntry{status: 200, body: []byte("old"), expires: time.Now().Add(-time.Second)})
+	cache.lastSweep = time.Now().Add(-2 * webCacheSweepInterval)
+	cache.set("GET /new", &webCacheEntry{status: 200, body: []byte("new"), expires: time.Now().Add(time.Minute)})
+
+	if _, ok := cache.entries["GET /old"]; ok {
+		t.Error("expired entry is not swept")
+	}
+
+	if cache.size != len("new") {
+		t.Errorf("wrong cached bodies size %d", cache.size)
+	}
+}
+
+func TestWebCacheKeySortsQuery(t *testing.T) {
+	a := webCacheKey(httptest.NewRequest("GET", "/items?b=2&a=1", nil))
+	b := webCacheKey(httptest.NewRequest("GET", "/items?a=1&b=2", nil))
+
+	if a != b || a != "GET /items?a=1&b=2" {
+		t.Errorf("keys differ: %q, %q", a, b)
+	}
+}
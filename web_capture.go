@@ -0,0 +1,179 @@
+package goapp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const capturedRedactedValue = "[REDACTED]"
+
+// headers never written to capture file as is
+var capturedRedactedHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization", "X-Api-Key"}
+
+// body JSON fields (name substrings) never written to capture file as is
+var capturedRedactedFields = []string{"password", "secret", "token"}
+
+// Request recorded to capture file
+type capturedRequest struct {
+	Time    time.Time   `json:"time"`
+	Status  int         `json:"status"`
+	Method  string      `json:"method"`
+	Url     string      `json:"url"` // path with query
+	Headers http.Header `json:"headers"`
+	Body    string      `json:"body"`
+}
+
+var captureFileMutex sync.Mutex
+
+// Middleware recording requests resulted in 5xx errors to webserver_capture_file (one JSON per line).
+func (app *AppBase) webCaptureMiddleware(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, 1048576))
+	if err != nil {
+		c.Next()
+		return
+	}
+
+	//give body back to handlers
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	c.Next()
+
+	if c.Writer.Status() < http.StatusInternalServerError {
+		return
+	}
+
+	record := capturedRequest{
+		Time:    time.Now(),
+		Status:  c.Writer.Status(),
+		Method:  c.Request.Method,
+		Url:     c.Request.URL.RequestURI(),
+		Headers: c.Request.Header.Clone(),
+		Body:    redactCapturedBody(body),
+	}
+
+	for _, name := range capturedRedactedHeaders {
+		if record.Headers.Get(name) != "" {
+			record.Headers.Set(name, capturedRedactedValue)
+		}
+	}
+
+	if err := appendCapturedRequest(app.baseSettings.WebserverCaptureFile, &record); err != nil {
+		logErrorf("Request capture ERROR: %s", err)
+	}
+}
+
+// Replaces sensitive top-level fields values in JSON object body
+func redactCapturedBody(body []byte) string {
+	var data map[string]any
+
+	if json.Unmarshal(body, &data) != nil {
+		return string(body) // not a JSON object
+	}
+
+	for name := range data {
+		for _, field := range capturedRedactedFields {
+			if strings.Contains(strings.ToLower(name), field) {
+				data[name] = capturedRedactedValue
+			}
+		}
+	}
+
+	redacted, _ := json.Marshal(data)
+	return string(redacted)
+}
+
+func appendCapturedRequest(filename string, record *capturedRequest) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	captureFileMutex.Lock()
+	defer captureFileMutex.Unlock()
+
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
+
+// Re-issues all requests from capture file against target base URL. Prints result for every request.
+func replayCapturedRequests(filename, target string) error {
+	// read all records first: target instance may append to the same file while replaying
+	records, err := readCapturedRequests(filename)
+	if err != nil {
+		return err
+	}
+
+	target = strings.TrimSuffix(target, "/")
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	for _, record := range records {
+		request, err := http.NewRequest(record.Method, target+record.Url, strings.NewReader(record.Body))
+		if err != nil {
+			return err
+		}
+
+		for name, values := range record.Headers {
+			if len(values) > 0 && values[0] == capturedRedactedValue {
+				continue
+			}
+
+			request.Header[name] = values
+		}
+
+		start := time.Now()
+		response, err := client.Do(request)
+
+		if err != nil {
+			logErrorf("%s %s: %s", record.Method, record.Url, err.Error())
+			continue
+		}
+
+		response.Body.Close()
+
+		log.Printf(
+			"%s %s: %d (was %d at %s) in %s\n", record.Method, record.Url, response.StatusCode,
+			record.Status, record.Time.Format(time.RFC3339), time.Since(start),
+		)
+	}
+
+	return nil
+}
+
+func readCapturedRequests(filename string) (records []capturedRequest, err error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1048576)
+
+	for scanner.Scan() {
+		var record capturedRequest
+
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	return records, scanner.Err()
+}
@@ -0,0 +1,65 @@
+package goapp
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Typed key for values passed from middleware to handlers with gin context.
+// Every key created with NewContextKey() is unique even if names are the same,
+// so there are no collisions between packages and no wrong type assertions.
+type ContextKey[T any] struct {
+	key string
+}
+
+// sequence to make context keys unique
+var contextKeySeq atomic.Int64
+
+// Creates new typed context key. name is used in messages only.
+func NewContextKey[T any](name string) ContextKey[T] {
+	return ContextKey[T]{key: fmt.Sprintf("goapp:%s#%d", name, contextKeySeq.Add(1))}
+}
+
+// Stores value in gin context.
+func (k ContextKey[T]) Set(c *gin.Context, value T) {
+	c.Set(k.key, value)
+}
+
+// Returns value from gin context. ok is false if value was not set.
+func (k ContextKey[T]) Get(c *gin.Context) (value T, ok bool) {
+	if v, exists := c.Get(k.key); exists {
+		value, ok = v.(T)
+	}
+
+	return value, ok
+}
+
+// Works like Get() but panics if value was not set.
+func (k ContextKey[T]) MustGet(c *gin.Context) T {
+	value, ok := k.Get(c)
+
+	if !ok {
+		logPanicf("context value %s is not set", k.key)
+	}
+
+	return value
+}
+
+// key for SetUser() and GetUser()
+var userContextKey = NewContextKey[any]("user")
+
+// Stores authenticated user in gin context (to be used by auth middleware).
+func SetUser(c *gin.Context, user any) {
+	userContextKey.Set(c, user)
+}
+
+// Returns user stored with SetUser(). ok is false if there is no user or it is not of type T.
+func GetUser[T any](c *gin.Context) (user T, ok bool) {
+	if v, exists := userContextKey.Get(c); exists {
+		user, ok = v.(T)
+	}
+
+	return user, ok
+}
@@ -0,0 +1,63 @@
+package goapp
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// methods allowed for cross-origin requests if webserver_cors_allowed_methods is empty
+var corsDefaultMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+
+// Answers CORS preflight requests and adds CORS headers to responses for allowed origins.
+// Requests from other origins are passed without CORS headers (so browser blocks them).
+func (app *AppBase) webCorsMiddleware(c *gin.Context) {
+	// response depends on Origin for allowed and not allowed origins both, so shared caches keep them apart
+	c.Writer.Header().Add("Vary", "Origin")
+
+	origin := c.GetHeader("Origin")
+
+	if origin == "" || !app.isCorsOriginAllowed(origin) {
+		c.Next()
+		return
+	}
+
+	settings := app.baseSettings
+
+	// origin is echoed (not "*") to work with credentials too
+	c.Header("Access-Control-Allow-Origin", origin)
+
+	// credentials are allowed for explicitly listed origins only (never for "*" match)
+	if settings.WebserverCorsAllowCredentials && slices.Contains(settings.WebserverCorsAllowedOrigins, origin) {
+		c.Header("Access-Control-Allow-Credentials", "true")
+	}
+
+	// preflight request
+	if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+		methods := settings.WebserverCorsAllowedMethods
+		if len(methods) == 0 {
+			methods = corsDefaultMethods
+		}
+
+		c.Header("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+
+		if headers := c.GetHeader("Access-Control-Request-Headers"); headers != "" {
+			c.Header("Access-Control-Allow-Headers", headers)
+		}
+
+		c.Header("Access-Control-Max-Age", "600")
+		c.AbortWithStatus(http.StatusNoContent)
+
+		return
+	}
+
+	c.Next()
+}
+
+func (app *AppBase) isCorsOriginAllowed(origin string) bool {
+	allowed := app.baseSettings.WebserverCorsAllowedOrigins
+
+	return slices.Contains(allowed, "*") || slices.Contains(allowed, origin)
+}
@@ -0,0 +1,63 @@
+package goapp
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Sets custom handler to render error page with given HTTP status code for non-API routes.
+// Handler is called only if nothing was written to response yet.
+func (app *AppBase) WebErrorPage(status int, handler gin.HandlerFunc) *AppBase {
+	app.webErrorPageHandlerList[status] = handler
+
+	return app //for method chaining
+}
+
+// Sets HTML template (loaded to gin engine with LoadHTMLGlob() or LoadHTMLFiles() in BuildWebRouterF)
+// to render error page with given HTTP status code for non-API routes.
+// Template gets "Status", "StatusText" and "Path" values.
+func (app *AppBase) WebErrorPageTemplate(status int, templateName string) *AppBase {
+	return app.WebErrorPage(status, func(c *gin.Context) {
+		c.HTML(status, templateName, gin.H{
+			"Status":     status,
+			"StatusText": http.StatusText(status),
+			"Path":       c.Request.URL.Path,
+		})
+	})
+}
+
+// Checks if request path belongs to web API
+func (app *AppBase) isWebApiPath(path string) bool {
+	return app.WebApiPathPrefix != "" && strings.HasPrefix(path, app.WebApiPathPrefix)
+}
+
+// Middleware rendering custom error pages for responses left without body.
+func (app *AppBase) webErrorPagesMiddleware(c *gin.Context) {
+	c.Next()
+
+	if c.Writer.Written() || app.isWebApiPath(c.Request.URL.Path) {
+		return
+	}
+
+	if handler, ok := app.webErrorPageHandlerList[c.Writer.Status()]; ok {
+		handler(c)
+	}
+}
+
+// Recovery handler reporting panic to ErrorReporterF and rendering custom 500 error page (if set) for non-API routes.
+func (app *AppBase) webErrorPagesRecovery(c *gin.Context, err any) {
+	app.reportPanic(c, err)
+
+	handler, ok := app.webErrorPageHandlerList[http.StatusInternalServerError]
+
+	if !ok || c.Writer.Written() || app.isWebApiPath(c.Request.URL.Path) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Abort()
+	c.Status(http.StatusInternalServerError)
+	handler(c)
+}
@@ -0,0 +1,156 @@
+package goapp
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Path of dev-mode recent requests inspector page. Add "?format=json" to get JSON.
+const InspectorPath = "/debug/requests"
+
+// number of recent requests kept by inspector
+const inspectorBufferSize = 100
+
+type inspectedRequest struct {
+	Time            time.Time     `json:"time"`
+	Method          string        `json:"method"`
+	Url             string        `json:"url"`
+	ClientIp        string        `json:"client_ip"`
+	Status          int           `json:"status"`
+	Duration        time.Duration `json:"duration"`
+	RequestHeaders  http.Header   `json:"request_headers"`
+	ResponseHeaders http.Header   `json:"response_headers"`
+}
+
+// Ring buffer of recent requests
+type webInspector struct {
+	mu      sync.Mutex
+	records []*inspectedRequest
+	next    int // position to write next record to
+}
+
+func newWebInspector() *webInspector {
+	return &webInspector{records: make([]*inspectedRequest, 0, inspectorBufferSize)}
+}
+
+func (inspector *webInspector) add(record *inspectedRequest) {
+	inspector.mu.Lock()
+	defer inspector.mu.Unlock()
+
+	if len(inspector.records) < inspectorBufferSize {
+		inspector.records = append(inspector.records, record)
+	} else {
+		inspector.records[inspector.next] = record
+	}
+
+	inspector.next = (inspector.next + 1) % inspectorBufferSize
+}
+
+// Returns recorded requests, most recent first.
+func (inspector *webInspector) list() []*inspectedRequest {
+	inspector.mu.Lock()
+	defer inspector.mu.Unlock()
+
+	list := make([]*inspectedRequest, 0, len(inspector.records))
+
+	for i := 1; i <= len(inspector.records); i++ {
+		index := (inspector.next - i + inspectorBufferSize) % inspectorBufferSize
+		list = append(list, inspector.records[index])
+	}
+
+	return list
+}
+
+// Middleware recording requests to inspector ring buffer (DEV mode only). Sensitive headers are redacted.
+func (app *AppBase) webInspectorMiddleware(c *gin.Context) {
+	if strings.HasPrefix(c.Request.URL.Path, "/debug/") {
+		c.Next()
+		return
+	}
+
+	start := time.Now()
+
+	c.Next()
+
+	record := &inspectedRequest{
+		Time:            start,
+		Method:          c.Request.Method,
+		Url:             c.Request.URL.RequestURI(),
+		ClientIp:        c.ClientIP(),
+		Status:          c.Writer.Status(),
+		Duration:        time.Since(start),
+		RequestHeaders:  c.Request.Header.Clone(),
+		ResponseHeaders: c.Writer.Header().Clone(),
+	}
+
+	for _, name := range append(capturedRedactedHeaders, "Set-Cookie") {
+		for _, headers := range []http.Header{record.RequestHeaders, record.ResponseHeaders} {
+			if headers.Get(name) != "" {
+				headers.Set(name, capturedRedactedValue)
+			}
+		}
+	}
+
+	app.webInspector.add(record)
+}
+
+var inspectorPageTemplate = template.Must(template.New("inspector").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .AppName }}: recent requests</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; }
+table { border-collapse: collapse; width: 100%; }
+td, th { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+.error { color: #c00; }
+details pre { margin: 4px 0; font-size: 12px; }
+</style>
+</head>
+<body>
+<h1>{{ .AppName }}: recent requests ({{ len .Requests }})</h1>
+<table>
+<tr><th>Time</th><th>Method</th><th>URL</th><th>Status</th><th>Duration</th><th>Client</th><th>Headers</th></tr>
+{{ range .Requests }}
+<tr{{ if ge .Status 400 }} class="error"{{ end }}>
+<td>{{ .Time.Format "15:04:05.000" }}</td>
+<td>{{ .Method }}</td>
+<td>{{ .Url }}</td>
+<td>{{ .Status }}</td>
+<td>{{ .Duration }}</td>
+<td>{{ .ClientIp }}</td>
+<td><details><summary>show</summary>
+<pre>{{ range $name, $values := .RequestHeaders }}{{ $name }}: {{ range $values }}{{ . }} {{ end }}
+{{ end }}</pre>
+<b>Response:</b>
+<pre>{{ range $name, $values := .ResponseHeaders }}{{ $name }}: {{ range $values }}{{ . }} {{ end }}
+{{ end }}</pre>
+</details></td>
+</tr>
+{{ end }}
+</table>
+</body>
+</html>
+`))
+
+func (app *AppBase) webInspectorGinHandler(c *gin.Context) {
+	list := app.webInspector.list()
+
+	if c.Query("format") == "json" {
+		c.JSON(http.StatusOK, list)
+		return
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	inspectorPageTemplate.Execute(c.Writer, gin.H{
+		"AppName":  app.AppName,
+		"Requests": list,
+	})
+}
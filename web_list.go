@@ -0,0 +1,49 @@
+package goapp
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Standard JSON envelope for paginated lists, so all list endpoints return same shape.
+type PaginatedList[T any] struct {
+	Data       []T   `json:"data"`
+	Total      int64 `json:"total"`
+	Page       int   `json:"page"`
+	PageSize   int   `json:"page_size"`
+	TotalPages int64 `json:"total_pages"`
+}
+
+// Builds list envelope from Paginate() results.
+func NewPaginatedList[T any](items []T, total int64, page, pageSize int) *PaginatedList[T] {
+	page, pageSize = normalizePage(page, pageSize)
+
+	if items == nil {
+		items = []T{} // "data": [] instead of null
+	}
+
+	return &PaginatedList[T]{
+		Data:       items,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: (total + int64(pageSize) - 1) / int64(pageSize),
+	}
+}
+
+// Writes Paginate() results as JSON list envelope.
+func WritePaginatedList[T any](c *gin.Context, items []T, total int64, page, pageSize int) {
+	c.JSON(http.StatusOK, NewPaginatedList(items, total, page, pageSize))
+}
+
+// Sets Paginate() results as list envelope fields of API response ("data", "total", "page" etc).
+func SetOutPaginatedList[T any](r *ApiRequest, items []T, total int64, page, pageSize int) {
+	list := NewPaginatedList(items, total, page, pageSize)
+
+	r.SetOutData("data", list.Data)
+	r.SetOutData("total", list.Total)
+	r.SetOutData("page", list.Page)
+	r.SetOutData("page_size", list.PageSize)
+	r.SetOutData("total_pages", list.TotalPages)
+}
@@ -0,0 +1,158 @@
+package goapp
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Default path of Prometheus metrics endpoint (see metrics_path setting)
+const MetricsPath = "/metrics"
+
+// request duration histogram buckets in seconds (same as Prometheus client defaults)
+var metricsDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type metricsRequestKey struct {
+	method string
+	path   string // route pattern, not actual URL (to keep labels count limited)
+	status int
+}
+
+type metricsHistogram struct {
+	buckets []uint64 // cumulative counts are calculated on output
+	sum     float64
+	count   uint64
+}
+
+// Requests duration histograms exposed in Prometheus text format
+type webMetrics struct {
+	mu         sync.Mutex
+	histograms map[metricsRequestKey]*metricsHistogram
+}
+
+func newWebMetrics() *webMetrics {
+	return &webMetrics{histograms: make(map[metricsRequestKey]*metricsHistogram)}
+}
+
+func (metrics *webMetrics) observe(key metricsRequestKey, duration time.Duration) {
+	seconds := duration.Seconds()
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+
+	histogram, ok := metrics.histograms[key]
+	if !ok {
+		histogram = &metricsHistogram{buckets: make([]uint64, len(metricsDurationBuckets))}
+		metrics.histograms[key] = histogram
+	}
+
+	for i, bound := range metricsDurationBuckets {
+		if seconds <= bound {
+			histogram.buckets[i]++
+			break
+		}
+	}
+
+	histogram.sum += seconds
+	histogram.count++
+}
+
+func (app *AppBase) webMetricsMiddleware(c *gin.Context) {
+	start := time.Now()
+
+	c.Next()
+
+	path := c.FullPath()
+	if path == "" {
+		path = "unmatched" // 404 and other not routed requests (raw path would make unlimited number of series)
+	}
+
+	app.webMetrics.observe(
+		metricsRequestKey{method: metricsMethodLabel(c.Request.Method), path: path, status: c.Writer.Status()},
+		time.Since(start),
+	)
+}
+
+// Standard HTTP methods are used as is, any other (client could send anything) are counted together,
+// so number of series is limited.
+func metricsMethodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return "other"
+	}
+}
+
+func (app *AppBase) webMetricsGinHandler(c *gin.Context) {
+	var sb strings.Builder
+
+	sb.WriteString("# HELP goapp_build_info Application build information.\n")
+	sb.WriteString("# TYPE goapp_build_info gauge\n")
+	fmt.Fprintf(&sb, "goapp_build_info{app=%s,version=%s,commit=%s,goversion=%s} 1\n",
+		metricsLabelValue(app.ExecutableName), metricsLabelValue(app.Version),
+		metricsLabelValue(app.BuildCommit), metricsLabelValue(runtime.Version()),
+	)
+
+	sb.WriteString("# HELP goapp_uptime_seconds Seconds since application start.\n")
+	sb.WriteString("# TYPE goapp_uptime_seconds gauge\n")
+	fmt.Fprintf(&sb, "goapp_uptime_seconds %g\n", app.Uptime().Seconds())
+
+	sb.WriteString("# HELP goapp_http_request_duration_seconds HTTP requests duration.\n")
+	sb.WriteString("# TYPE goapp_http_request_duration_seconds histogram\n")
+
+	app.webMetrics.mu.Lock()
+
+	keys := make([]metricsRequestKey, 0, len(app.webMetrics.histograms))
+	for key := range app.webMetrics.histograms {
+		keys = append(keys, key)
+	}
+
+	// stable output order
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].path != keys[j].path {
+			return keys[i].path < keys[j].path
+		}
+
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+
+		return keys[i].status < keys[j].status
+	})
+
+	for _, key := range keys {
+		histogram := app.webMetrics.histograms[key]
+		labels := fmt.Sprintf("method=%s,path=%s,status=%s",
+			metricsLabelValue(key.method), metricsLabelValue(key.path), metricsLabelValue(strconv.Itoa(key.status)),
+		)
+
+		var cumulative uint64
+
+		for i, bound := range metricsDurationBuckets {
+			cumulative += histogram.buckets[i]
+			fmt.Fprintf(&sb, "goapp_http_request_duration_seconds_bucket{%s,le=\"%g\"} %d\n", labels, bound, cumulative)
+		}
+
+		fmt.Fprintf(&sb, "goapp_http_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, histogram.count)
+		fmt.Fprintf(&sb, "goapp_http_request_duration_seconds_sum{%s} %g\n", labels, histogram.sum)
+		fmt.Fprintf(&sb, "goapp_http_request_duration_seconds_count{%s} %d\n", labels, histogram.count)
+	}
+
+	app.webMetrics.mu.Unlock()
+
+	c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(sb.String()))
+}
+
+// Quotes and escapes label value according to Prometheus text format
+func metricsLabelValue(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
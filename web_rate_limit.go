@@ -0,0 +1,100 @@
+package goapp
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// buckets not used for this time are removed (they are full again anyway)
+const rateLimitSweepInterval = time.Minute
+
+type rateLimitBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Token bucket rate limiter keyed by client connection IP: bucket of burst size is refilled with rate tokens
+// per second, every request takes one token.
+type webRateLimiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	buckets   map[string]*rateLimitBucket
+	lastSweep time.Time
+}
+
+func newWebRateLimiter(rate float64, burst uint) *webRateLimiter {
+	if burst == 0 {
+		burst = uint(math.Max(1, math.Ceil(rate)))
+	}
+
+	return &webRateLimiter{
+		rate:      rate,
+		burst:     float64(burst),
+		buckets:   make(map[string]*rateLimitBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Takes token from key's bucket. Returns false and time to wait for next token if bucket is empty.
+func (limiter *webRateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	if now.Sub(limiter.lastSweep) > rateLimitSweepInterval {
+		limiter.sweep(now)
+	}
+
+	bucket, ok := limiter.buckets[key]
+	if !ok {
+		bucket = &rateLimitBucket{tokens: limiter.burst, updated: now}
+		limiter.buckets[key] = bucket
+	}
+
+	bucket.tokens = math.Min(limiter.burst, bucket.tokens+now.Sub(bucket.updated).Seconds()*limiter.rate)
+	bucket.updated = now
+
+	if bucket.tokens < 1 {
+		return false, time.Duration((1 - bucket.tokens) / limiter.rate * float64(time.Second))
+	}
+
+	bucket.tokens--
+
+	return true, 0
+}
+
+func (limiter *webRateLimiter) sweep(now time.Time) {
+	// full bucket is the same as missing one
+	fullAfter := time.Duration(limiter.burst / limiter.rate * float64(time.Second))
+
+	for key, bucket := range limiter.buckets {
+		if now.Sub(bucket.updated) > fullAfter {
+			delete(limiter.buckets, key)
+		}
+	}
+
+	limiter.lastSweep = now
+}
+
+// Replies 429 with Retry-After header to API clients exceeding webapi_rate_limit.
+func (app *AppBase) webApiRateLimitMiddleware(c *gin.Context) {
+	// connection address: X-Forwarded-For could be set by any client as no trusted proxies are configured
+	ok, wait := app.webApiRateLimiter.allow(c.RemoteIP(), time.Now())
+
+	if !ok {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+			"status":  "error",
+			"message": "too many requests",
+		})
+
+		return
+	}
+
+	c.Next()
+}
@@ -3,12 +3,12 @@ package goapp
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
+	"path"
+	"slices"
 	"strings"
 
 	"github.com/gin-contrib/sessions"
-	"github.com/gin-contrib/sessions/cookie"
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,30 +17,134 @@ func (app *AppBase) buildGinWebRouter() http.Handler {
 	gin.SetMode(gin.ReleaseMode)
 
 	//Initialize Cookie-based session store
-	sessionStore := cookie.NewStore([]byte(app.baseSettings.WebserverCookieSecret))
+	sessionStore := app.newSessionStore()
 
 	// Prepare router
 	app.ginEngine = gin.New()
 
+	// requests validation starts soon
+	validationRegistrationClosed.Store(true)
+
+	//tracing spans cover all other middlewares
+	if app.tracingShutdownF != nil {
+		app.ginEngine.Use(app.tracingMiddleware)
+	}
+
+	//failed requests capturing (installed before recovery middleware to see panics results)
+	if app.baseSettings.WebserverCaptureFile != "" {
+		app.ginEngine.Use(app.webCaptureMiddleware)
+		logInfof("Failed requests capturing enabled: %s", app.baseSettings.WebserverCaptureFile)
+	}
+
+	//requests metrics (installed before recovery middleware to count panics as 500)
+	if app.baseSettings.MetricsEnabled {
+		app.webMetrics = newWebMetrics()
+		app.ginEngine.Use(app.webMetricsMiddleware)
+	}
+
+	//extended logging if requested (installed before recovery middleware to log panics as 500)
+	if app.WebRouterLogRequests {
+		app.ginEngine.Use(app.requestLoggerMiddleware)
+		logInfof("Extended queries logging enabled.")
+	}
+
 	// Recovery middleware recovers from any panics and writes a 500 if there was one.
-	app.ginEngine.Use(gin.Recovery())
+	app.ginEngine.Use(gin.CustomRecovery(app.webErrorPagesRecovery))
+
+	// 5xx responses reporting
+	if app.ErrorReporterF != nil && app.ReportServerErrors {
+		app.ginEngine.Use(app.errorReporterMiddleware)
+	}
+
+	// recent requests inspector (exposes request headers, so never in production)
+	if app.IsDevMode() && !app.baseSettings.Production {
+		app.webInspector = newWebInspector()
+		app.ginEngine.Use(app.webInspectorMiddleware)
+	}
+
+	// CORS headers and preflight requests (before routes and sessions)
+	if len(app.baseSettings.WebserverCorsAllowedOrigins) > 0 {
+		app.ginEngine.Use(app.webCorsMiddleware)
+	}
+
+	// custom error pages for non-API routes
+	if len(app.webErrorPageHandlerList) > 0 {
+		app.ginEngine.Use(app.webErrorPagesMiddleware)
+	}
 
 	// use session store
 	app.ginEngine.Use(sessions.Sessions(app.ExecutableName, sessionStore))
 
-	//extended logging if requested
-	if app.WebRouterLogRequests {
-		app.ginEngine.Use(gin.Logger())
-		log.Println("Extended queries logging enabled.")
+	//responses cache
+	if len(app.baseSettings.WebserverCacheRoutes) > 0 {
+		app.webCache = newWebResponseCache()
+		app.ginEngine.Use(app.webCacheMiddleware)
 	}
 
 	//API routes
 	if app.WebApiPathPrefix != "" {
-		app.ginEngine.POST("/api/*any", (app).webApiRequestGinHandler)
+		apiHandlers := []gin.HandlerFunc{}
+
+		// rate limit goes first to protect authentication from brute force too
+		if app.baseSettings.WebApiRateLimit > 0 {
+			app.webApiRateLimiter = newWebRateLimiter(app.baseSettings.WebApiRateLimit, app.baseSettings.WebApiRateBurst)
+			apiHandlers = append(apiHandlers, app.webApiRateLimitMiddleware)
+		}
+
+		// authentication goes before idempotency, so keys are scoped by authenticated identity
+		if app.ApiAuthF != nil {
+			apiHandlers = append(apiHandlers, app.webApiAuthMiddleware)
+		}
 
-		if app.WebApiEnableGet {
-			app.ginEngine.GET("/api/*any", (app).webApiRequestGinHandler)
+		if app.baseSettings.WebApiIdempotencyTtl > 0 {
+			apiHandlers = append(apiHandlers, app.webApiIdempotencyMiddleware)
+			app.Go(app.idempotencyCleanupLoop)
 		}
+
+		apiHandlers = append(apiHandlers, app.webApiRequestGinHandler)
+
+		// route for every method accepted by any handler, exact method is checked by handler
+		for _, method := range app.webApiRouteMethods() {
+			app.ginEngine.Handle(method, "/api/*any", apiHandlers...)
+		}
+	}
+
+	//static assets
+	for urlPrefix, assets := range app.webStaticAssetsList {
+		// root catch-all route would conflict with all other routes
+		if urlPrefix == "/" {
+			app.ginEngine.NoRoute(app.webStaticNoRouteHandler)
+			continue
+		}
+
+		app.ginEngine.GET(path.Join(urlPrefix, "/*filepath"), assets.ginHandler)
+		app.ginEngine.HEAD(path.Join(urlPrefix, "/*filepath"), assets.ginHandler)
+	}
+
+	//health check
+	if app.baseSettings.HealthCheckPath != "" {
+		app.ginEngine.GET(app.baseSettings.HealthCheckPath, app.healthGinHandler)
+	}
+
+	if app.webMetrics != nil {
+		app.ginEngine.GET(app.baseSettings.MetricsPath, app.webMetricsGinHandler)
+	}
+
+	if app.baseSettings.LivenessCheckPath != "" {
+		app.ginEngine.GET(app.baseSettings.LivenessCheckPath, app.livenessGinHandler)
+	}
+
+	if app.baseSettings.ReadinessCheckPath != "" {
+		app.ginEngine.GET(app.baseSettings.ReadinessCheckPath, app.readinessGinHandler)
+	}
+
+	//runtime stats and requests inspector for quick checks in DEV mode only
+	if app.IsDevMode() {
+		app.ginEngine.GET(RuntimeStatsPath, app.runtimeStatsGinHandler)
+	}
+
+	if app.webInspector != nil {
+		app.ginEngine.GET(InspectorPath, app.webInspectorGinHandler)
 	}
 
 	// user provided routes
@@ -58,19 +162,67 @@ func (app *AppBase) webApiRequestGinHandler(c *gin.Context) {
 	)
 
 	path := strings.TrimPrefix(c.Request.URL.Path, app.WebApiPathPrefix)
+
+	if _, ok := app.webApiHandlerList[path]; ok {
+		if allowed := app.webApiAllowedMethods(path); !slices.Contains(allowed, c.Request.Method) {
+			c.Header("Allow", strings.Join(allowed, ", "))
+			c.AbortWithStatusJSON(http.StatusMethodNotAllowed, gin.H{
+				"status":  "error",
+				"message": "method " + c.Request.Method + " is not allowed",
+			})
+
+			return
+		}
+	}
+
+	// per-handler middleware (c.Next() in them does not call handler, see ApiHandlerWithMiddleware())
+	for _, mw := range app.webApiMiddlewareList[path] {
+		mw(c)
+
+		if c.IsAborted() {
+			return
+		}
+	}
+
 	api_request, err = newApiRequest(c)
 
+	errorStatus := http.StatusInternalServerError
+
 	if err == nil {
 		if handler, ok := app.webApiHandlerList[path]; ok {
+			if schema, ok := app.webApiSchemaList[path]; ok {
+				if violations := validateApiRequestSchema(schema, api_request.body); violations != nil {
+					writeApiSchemaViolations(c, violations)
+					return
+				}
+			}
+
 			err = handler(api_request)
 		} else {
 			err = fmt.Errorf("path '%s' not found", path)
+			errorStatus = http.StatusNotFound
 		}
 	}
 
 	if err != nil {
-		log.Println("API Request error: ", err)
-		http.Error(c.Writer, err.Error(), http.StatusInternalServerError)
+		logErrorf("API Request error: %s", err)
+
+		if app.WebApiUseEnvelope {
+			c.AbortWithStatusJSON(errorStatus, apiEnvelope{Ok: false, Error: err.Error()})
+		} else {
+			http.Error(c.Writer, err.Error(), http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	// reply was already written by handler (see ApiRequest.BindJSON() and ApiJson())
+	if c.IsAborted() {
+		return
+	}
+
+	if app.WebApiUseEnvelope {
+		writeApiEnvelope(c, api_request.outData)
 		return
 	}
 
@@ -83,3 +235,74 @@ func (app *AppBase) webApiRequestGinHandler(c *gin.Context) {
 	c.Writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	json.NewEncoder(c.Writer).Encode(api_request.outData)
 }
+
+// Returns HTTP methods accepted by API route.
+func (app *AppBase) webApiAllowedMethods(path string) []string {
+	if methods, ok := app.webApiMethodList[path]; ok {
+		return methods
+	}
+
+	if app.WebApiEnableGet {
+		return []string{http.MethodPost, http.MethodGet}
+	}
+
+	return []string{http.MethodPost}
+}
+
+// Returns union of methods accepted by all API routes (used to register catch-all API routes).
+func (app *AppBase) webApiRouteMethods() []string {
+	methods := []string{http.MethodPost}
+
+	if app.WebApiEnableGet {
+		methods = append(methods, http.MethodGet)
+	}
+
+	for _, list := range app.webApiMethodList {
+		for _, method := range list {
+			if !slices.Contains(methods, method) {
+				methods = append(methods, method)
+			}
+		}
+	}
+
+	return methods
+}
+
+// API response envelope (see WebApiUseEnvelope)
+type apiEnvelope struct {
+	Ok     bool              `json:"ok"`
+	Data   any               `json:"data"`
+	Error  string            `json:"error,omitempty"`
+	Errors map[string]string `json:"errors,omitempty"` // validation errors (field name => message)
+}
+
+// Wraps handler output to envelope: "status" and "message" values set with SetOkStatus() and
+// SetErrorStatus() are converted to "ok" and "error" fields (error status results in 400 reply),
+// other values become "data" object.
+func writeApiEnvelope(c *gin.Context, outData map[string]any) {
+	envelope := apiEnvelope{Ok: true}
+	status := http.StatusOK
+
+	data := make(map[string]any, len(outData))
+	for name, value := range outData {
+		data[name] = value
+	}
+
+	delete(data, "status")
+
+	if outData["status"] == "error" {
+		envelope.Ok = false
+		envelope.Error = fmt.Sprintf("%v", outData["message"])
+		status = http.StatusBadRequest
+
+		delete(data, "message")
+	} else if message, ok := data["message"]; ok && message == "" {
+		delete(data, "message")
+	}
+
+	if len(data) > 0 {
+		envelope.Data = data
+	}
+
+	c.JSON(status, envelope)
+}
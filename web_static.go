@@ -0,0 +1,251 @@
+package goapp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Cache-Control for assets with content hash in filename (like "app.3f9c1e2a.js"): content never changes.
+const webStaticHashedCacheControl = "public, max-age=31536000, immutable"
+
+// Cache-Control for other assets: short lifetime, revalidated with ETag after it.
+const webStaticCacheControl = "public, max-age=300"
+
+// Cache-Control for SPA index.html: always revalidated, so new deployments are picked up right away.
+const webStaticIndexCacheControl = "no-cache"
+
+// Static assets filesystem (usually embed.FS) served under URL prefix.
+type webStaticAssets struct {
+	fsys fs.FS
+	spa  bool // serve index.html for directories and client-side routes (see ServeStaticFS())
+
+	mu    sync.Mutex
+	etags map[string]string // file path => strong ETag (files are not changing, so computed once)
+}
+
+// Serves files from fsys (usually embed.FS) with GET and HEAD requests under urlPrefix.
+// Responses get strong content hash based ETag, so conditional requests are answered with 304.
+// Files with content hash in name are cached by browsers for a year, others for five minutes.
+func (app *AppBase) StaticAssets(urlPrefix string, fsys fs.FS) *AppBase {
+	urlPrefix = "/" + strings.Trim(urlPrefix, "/")
+
+	app.webStaticAssetsList[urlPrefix] = &webStaticAssets{
+		fsys:  fsys,
+		etags: make(map[string]string),
+	}
+
+	return app //for method chaining
+}
+
+// Serves single page application (front-end built with Vite, webpack etc, usually embedded with go:embed)
+// from fsys under urlPrefix. Works like StaticAssets() plus index.html is served for directories and for
+// paths without extension (client-side routes like "/users/42"), missing files with extension get 404.
+// Root urlPrefix ("/") is served for not routed requests only, so API, health check and other routes
+// are not shadowed (NoRoute handler set in BuildWebRouterF replaces it).
+func (app *AppBase) ServeStaticFS(urlPrefix string, fsys fs.FS) *AppBase {
+	app.StaticAssets(urlPrefix, fsys)
+	app.webStaticAssetsList["/"+strings.Trim(urlPrefix, "/")].spa = true
+
+	return app //for method chaining
+}
+
+// Handler for requests not matching any route (root SPA assets, see ServeStaticFS()).
+func (app *AppBase) webStaticNoRouteHandler(c *gin.Context) {
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return // gin default 404 reply
+	}
+
+	if app.isWebApiPath(c.Request.URL.Path) {
+		return
+	}
+
+	app.webStaticAssetsList["/"].ginHandler(c)
+}
+
+func (assets *webStaticAssets) ginHandler(c *gin.Context) {
+	filepath := c.Param("filepath")
+	if filepath == "" {
+		filepath = c.Request.URL.Path // not routed request
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+filepath), "/")
+	if name == "" {
+		name = "." // prefix root
+	}
+
+	if !fs.ValidPath(name) {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if assets.spa {
+		name = assets.spaFileName(name)
+	}
+
+	if name == "." {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	content, err := assets.open(name)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if closer, ok := content.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	etag, err := assets.etag(name)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("ETag", etag)
+
+	if assets.spa && path.Base(name) == "index.html" {
+		c.Header("Cache-Control", webStaticIndexCacheControl)
+	} else if isHashedAssetName(name) {
+		c.Header("Cache-Control", webStaticHashedCacheControl)
+	} else {
+		c.Header("Cache-Control", webStaticCacheControl)
+	}
+
+	// handles If-None-Match, Range and sets Content-Type by file extension
+	http.ServeContent(c.Writer, c.Request, name, time.Time{}, content)
+}
+
+// Maps request file name to index.html for directories and client-side routes.
+func (assets *webStaticAssets) spaFileName(name string) string {
+	if name == "." {
+		return "index.html"
+	}
+
+	if stat, err := fs.Stat(assets.fsys, name); err == nil {
+		if stat.IsDir() {
+			return path.Join(name, "index.html")
+		}
+
+		return name
+	}
+
+	// missing assets ("app.js", "logo.png") are not routes
+	if path.Ext(name) == "" {
+		return "index.html"
+	}
+
+	return name
+}
+
+// Opens regular file for reading. embed.FS files are seekable already, others are read to memory.
+func (assets *webStaticAssets) open(name string) (io.ReadSeeker, error) {
+	file, err := assets.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if stat, err := file.Stat(); err != nil || !stat.Mode().IsRegular() {
+		file.Close()
+		return nil, fs.ErrNotExist
+	}
+
+	if seeker, ok := file.(io.ReadSeeker); ok {
+		return seeker, nil
+	}
+
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(data), nil
+}
+
+func (assets *webStaticAssets) etag(name string) (string, error) {
+	assets.mu.Lock()
+	defer assets.mu.Unlock()
+
+	if etag, ok := assets.etags[name]; ok {
+		return etag, nil
+	}
+
+	data, err := fs.ReadFile(assets.fsys, name)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(hash[:16]) + `"`
+
+	assets.etags[name] = etag
+
+	return etag, nil
+}
+
+// Checks if filename contains content hash: last dot or dash separated part of name (without extension) is
+// lowercase hex of at least 8 digits (webpack, "app.3f9c1e2a.js"), 8 letters and digits base64-like
+// mixed case (Vite, "index-B7x2kQ9d.css") or 8 uppercase base32 (esbuild, "chunk-5UJ2X7QK.js").
+// Hash should have both letters and digits, so names like "report-20240101.pdf" are not taken for hashed.
+func isHashedAssetName(name string) bool {
+	name = path.Base(name)
+	name = strings.TrimSuffix(name, path.Ext(name))
+
+	hash := name[strings.LastIndexAny(name, ".-")+1:]
+
+	if len(hash) < 8 || len(hash) == len(name) {
+		return false
+	}
+
+	var digits, hexLetters, lower, upper, base32Digits int
+
+	for _, r := range hash {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+
+			if r >= '2' && r <= '7' {
+				base32Digits++
+			}
+		case r >= 'a' && r <= 'f':
+			hexLetters++
+			lower++
+		case r >= 'a' && r <= 'z':
+			lower++
+		case r >= 'A' && r <= 'Z':
+			upper++
+		default:
+			return false
+		}
+	}
+
+	if digits == 0 {
+		return false
+	}
+
+	switch {
+	case hexLetters > 0 && hexLetters == lower && upper == 0:
+		return true // hex
+	case len(hash) != 8:
+		return false
+	case lower > 0 && upper > 0:
+		return true // base64-like
+	case lower == 0 && upper > 0 && base32Digits == digits:
+		return true // base32
+	default:
+		return false
+	}
+}
@@ -0,0 +1,80 @@
+package goapp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIsHashedAssetName(t *testing.T) {
+	cases := map[string]bool{
+		"app.3f9c1e2a.js":               true,  // webpack hex
+		"assets/vendor.0a1b2c3d4e5f.js": true,  // long hex
+		"index-B7x2kQ9d.css":            true,  // Vite
+		"chunk-5UJ2X7QK.js":             true,  // esbuild base32
+		"app.js":                        false, // no hash
+		"roboto-regular400.woff2":       false, // word with digits
+		"report-2024final.pdf":          false,
+		"report-20240101.pdf":           false, // date, digits only
+		"font_ab12cd34.woff2":           false, // no separator before hash
+		"logo-ab_12cd34.png":            false, // underscore is not a hash character
+		"icon-deadbeef.png":             false, // hex letters only
+		"photo-Summer2024.jpg":          false, // mixed case of other length
+		"3f9c1e2a.js":                   false, // hash only, no name
+	}
+
+	for name, expected := range cases {
+		if got := isHashedAssetName(name); got != expected {
+			t.Errorf("isHashedAssetName(%q) = %v, expected %v", name, got, expected)
+		}
+	}
+}
+
+func TestStaticAssetsCacheHeaders(t *testing.T) {
+	app := NewAppBase(&testShutdownSettings{})
+	app.StaticAssets("/static", fstest.MapFS{
+		"app.3f9c1e2a.js": {Data: []byte("hashed")},
+		"logo.png":        {Data: []byte("plain")},
+	})
+
+	engine := gin.New()
+	engine.GET("/static/*filepath", app.webStaticAssetsList["/static"].ginHandler)
+
+	get := func(path, etag string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		if etag != "" {
+			request.Header.Set("If-None-Match", etag)
+		}
+
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	hashed := get("/static/app.3f9c1e2a.js", "")
+	if hashed.Code != http.StatusOK || hashed.Header().Get("Cache-Control") != webStaticHashedCacheControl {
+		t.Errorf("hashed asset: status %d, Cache-Control %q", hashed.Code, hashed.Header().Get("Cache-Control"))
+	}
+
+	plain := get("/static/logo.png", "")
+	if plain.Code != http.StatusOK || plain.Header().Get("Cache-Control") != webStaticCacheControl {
+		t.Errorf("plain asset: status %d, Cache-Control %q", plain.Code, plain.Header().Get("Cache-Control"))
+	}
+
+	etag := plain.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("ETag is not set")
+	}
+
+	if revalidated := get("/static/logo.png", etag); revalidated.Code != http.StatusNotModified {
+		t.Errorf("conditional request: expected 304, got %d", revalidated.Code)
+	}
+
+	if missing := get("/static/missing.js", ""); missing.Code != http.StatusNotFound {
+		t.Errorf("missing asset: expected 404, got %d", missing.Code)
+	}
+}
@@ -0,0 +1,273 @@
+package goapp
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	gorm "gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// response is flushed to client every streamFlushRows rows
+const streamFlushRows = 100
+
+// Streams query results row by row as JSON array (or NDJSON - one JSON object per line - if ndjson is true)
+// without loading whole result set to memory. Use it for exports and other huge lists.
+// If query is nil, all model objects are streamed. Streaming stops when client disconnects.
+// Response is already started when error happens during rows reading, so it is returned to be logged only.
+func StreamJSON[ModelT any](c *gin.Context, query *gorm.DB, ndjson bool) error {
+	modelType := reflect.TypeFor[ModelT]()
+
+	if DbSchema.Db() == nil {
+		return errors.New("database is not opened")
+	}
+
+	if !DbSchema.HasModel(modelType) {
+		return errors.New("model " + modelType.String() + " is not registered with AddModel()")
+	}
+
+	if query == nil {
+		query = DbSchema.Db()
+	}
+
+	ctx := c.Request.Context()
+
+	rows, err := query.WithContext(ctx).Model(new(ModelT)).Rows()
+	if err != nil {
+		logErrorf("Query ERROR: %s", err)
+		return err
+	}
+	defer rows.Close()
+
+	if ndjson {
+		c.Header("Content-Type", "application/x-ndjson; charset=UTF-8")
+	} else {
+		c.Header("Content-Type", "application/json; charset=UTF-8")
+	}
+
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer) // adds newline after every object
+
+	if !ndjson {
+		c.Writer.WriteString("[")
+	}
+
+	count := 0
+
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return err // client is gone
+		}
+
+		var modelObject ModelT
+
+		if err := query.ScanRows(rows, &modelObject); err != nil {
+			return err
+		}
+
+		if !ndjson && count > 0 {
+			c.Writer.WriteString(",")
+		}
+
+		if err := encoder.Encode(&modelObject); err != nil {
+			return err
+		}
+
+		count++
+
+		if count%streamFlushRows == 0 {
+			c.Writer.Flush()
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if !ndjson {
+		c.Writer.WriteString("]")
+	}
+
+	c.Writer.Flush()
+
+	return nil
+}
+
+// Streams query results as CSV file download (with header row) without loading whole result set to memory.
+// columns are database column names to export in given order, all model columns (in fields order) are
+// exported if none given. If query is nil, all model objects are exported. Streaming stops when client disconnects.
+// Response is already started when error happens during rows reading, so it is returned to be logged only.
+func StreamCSV[ModelT any](c *gin.Context, query *gorm.DB, filename string, columns ...string) error {
+	modelType := reflect.TypeFor[ModelT]()
+
+	if DbSchema.Db() == nil {
+		return errors.New("database is not opened")
+	}
+
+	if !DbSchema.HasModel(modelType) {
+		return errors.New("model " + modelType.String() + " is not registered with AddModel()")
+	}
+
+	modelSchema, err := schema.Parse(new(ModelT), &sync.Map{}, dbNamingStrategy)
+	if err != nil {
+		return err
+	}
+
+	fields, err := csvExportFields(modelSchema, columns)
+	if err != nil {
+		return err
+	}
+
+	if query == nil {
+		query = DbSchema.Db()
+	}
+
+	ctx := c.Request.Context()
+
+	rows, err := query.WithContext(ctx).Model(new(ModelT)).Rows()
+	if err != nil {
+		logErrorf("Query ERROR: %s", err)
+		return err
+	}
+	defer rows.Close()
+
+	c.Header("Content-Type", "text/csv; charset=UTF-8")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+
+	header := make([]string, len(fields))
+	for i, field := range fields {
+		header[i] = field.DBName
+	}
+
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(fields))
+	count := 0
+
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return err // client is gone
+		}
+
+		var modelObject ModelT
+
+		if err := query.ScanRows(rows, &modelObject); err != nil {
+			return err
+		}
+
+		objectValue := reflect.ValueOf(&modelObject).Elem()
+
+		for i, field := range fields {
+			record[i] = csvValue(field, objectValue)
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+
+		count++
+
+		if count%streamFlushRows == 0 {
+			writer.Flush()
+			c.Writer.Flush()
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	writer.Flush()
+	c.Writer.Flush()
+
+	return writer.Error()
+}
+
+// Returns model fields to export: all database columns or ones listed in columns (in that order).
+func csvExportFields(modelSchema *schema.Schema, columns []string) ([]*schema.Field, error) {
+	if len(columns) == 0 {
+		fields := make([]*schema.Field, 0, len(modelSchema.Fields))
+
+		for _, field := range modelSchema.Fields {
+			if field.DBName != "" {
+				fields = append(fields, field)
+			}
+		}
+
+		return fields, nil
+	}
+
+	fields := make([]*schema.Field, len(columns))
+
+	for i, column := range columns {
+		field := modelSchema.LookUpField(column)
+
+		if field == nil || field.DBName == "" {
+			return nil, fmt.Errorf("model %s has no column '%s'", modelSchema.Name, column)
+		}
+
+		fields[i] = field
+	}
+
+	return fields, nil
+}
+
+// Formats field value for CSV: times in RFC 3339, NULLs as empty strings.
+func csvValue(field *schema.Field, objectValue reflect.Value) string {
+	value, isZero := field.ValueOf(context.Background(), objectValue)
+
+	if valuer, ok := value.(driver.Valuer); ok {
+		if isZero {
+			return "" // NULL-able types (gorm.DeletedAt, sql.NullString etc)
+		}
+
+		v, err := valuer.Value()
+		if err != nil || v == nil {
+			return ""
+		}
+
+		value = v
+	}
+
+	if value == nil {
+		return ""
+	}
+
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return ""
+		}
+
+		rv = rv.Elem()
+	}
+
+	switch v := rv.Interface().(type) {
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+
+		return v.Format(time.RFC3339)
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
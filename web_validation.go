@@ -0,0 +1,201 @@
+package goapp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// set when web router is built, validator is not safe to modify while it is used by requests
+var validationRegistrationClosed atomic.Bool
+
+// Registers custom validation rule for `binding:"..."` struct tags (used by BindAndValidate() and gin
+// binding). Should be called before web router is built (from main() or InitF / PreRunF).
+func RegisterValidation(tag string, fn validator.Func) error {
+	if validationRegistrationClosed.Load() {
+		return fmt.Errorf("validation '%s' should be registered before web router is built", tag)
+	}
+
+	engine, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("gin binding validator is not go-playground/validator")
+	}
+
+	return engine.RegisterValidation(tag, fn)
+}
+
+// Binds request data (JSON body, form or query depending on request) to obj and validates it with
+// `binding:"..."` tags. On failure writes error response and returns false:
+//   - 422 with "errors" object (field name => message) if validation failed
+//   - 400 if request data can not be parsed
+//
+// Field names are taken from `json` tags (dot-separated for nested structs).
+func BindAndValidate(c *gin.Context, obj any) bool {
+	err := c.ShouldBind(obj)
+	if err == nil {
+		return true
+	}
+
+	abortWithBindError(c, http.StatusUnprocessableEntity, err, obj)
+
+	return false
+}
+
+// Writes binding error response: validationStatus with "errors" object (field name => message) if
+// validation failed, 400 if request data can not be parsed.
+func abortWithBindError(c *gin.Context, validationStatus int, err error, obj any) {
+	var validationErrors validator.ValidationErrors
+
+	if errors.As(err, &validationErrors) {
+		c.AbortWithStatusJSON(validationStatus, gin.H{
+			"status":  "error",
+			"message": "validation failed",
+			"errors":  validationErrorsMap(validationErrors, reflect.TypeOf(obj)),
+		})
+	} else {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+}
+
+// Builds field name => message map from validator errors.
+func validationErrorsMap(validationErrors validator.ValidationErrors, objType reflect.Type) map[string]string {
+	list := make(map[string]string, len(validationErrors))
+
+	for _, fieldError := range validationErrors {
+		name := validationFieldName(objType, fieldError.StructNamespace())
+
+		//first failed rule only
+		if _, exists := list[name]; !exists {
+			list[name] = validationMessage(fieldError)
+		}
+	}
+
+	return list
+}
+
+// Converts "Struct.Field.SubField" namespace to "field.sub_field" using json tags (struct field names
+// are used if there is no json tag).
+func validationFieldName(t reflect.Type, namespace string) string {
+	parts := strings.Split(namespace, ".")
+
+	//first part is struct name (there is no one for anonymous structs)
+	structType := t
+	for structType != nil && structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+
+	if structType == nil || structType.Name() != "" {
+		parts = parts[1:]
+	}
+
+	names := make([]string, 0, len(parts))
+
+	for _, part := range parts {
+		//slice and map items: "Items[0]"
+		fieldName, index, _ := strings.Cut(part, "[")
+		if index != "" {
+			index = "[" + index
+		}
+
+		for t != nil && t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		name := fieldName
+
+		if t != nil && t.Kind() == reflect.Struct {
+			if field, ok := t.FieldByName(fieldName); ok {
+				if tag, _, _ := strings.Cut(field.Tag.Get("json"), ","); tag != "" && tag != "-" {
+					name = tag
+				}
+
+				t = field.Type
+			} else {
+				t = nil
+			}
+		} else {
+			t = nil
+		}
+
+		// go to item type
+		if t != nil && index != "" {
+			for t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+
+			switch t.Kind() {
+			case reflect.Slice, reflect.Array, reflect.Map:
+				t = t.Elem()
+			default:
+				t = nil
+			}
+		}
+
+		names = append(names, name+index)
+	}
+
+	return strings.Join(names, ".")
+}
+
+// Returns human readable message for failed validation rule.
+func validationMessage(fieldError validator.FieldError) string {
+	param := fieldError.Param()
+
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "should be valid email address"
+	case "url":
+		return "should be valid URL"
+	case "uuid":
+		return "should be valid UUID"
+	case "min":
+		if isValidationLengthKind(fieldError.Kind()) {
+			return fmt.Sprintf("should be at least %s characters long", param)
+		}
+
+		return "should be at least " + param
+	case "max":
+		if isValidationLengthKind(fieldError.Kind()) {
+			return fmt.Sprintf("should be at most %s characters long", param)
+		}
+
+		return "should be at most " + param
+	case "len":
+		return fmt.Sprintf("should be %s characters long", param)
+	case "gt":
+		return "should be greater than " + param
+	case "gte":
+		return "should be greater than or equal to " + param
+	case "lt":
+		return "should be less than " + param
+	case "lte":
+		return "should be less than or equal to " + param
+	case "oneof":
+		return "should be one of: " + strings.Join(strings.Fields(param), ", ")
+	case "eqfield":
+		return "should be equal to " + param
+	}
+
+	if param != "" {
+		return fmt.Sprintf("failed '%s=%s' validation", fieldError.Tag(), param)
+	}
+
+	return fmt.Sprintf("failed '%s' validation", fieldError.Tag())
+}
+
+// Strings, slices and maps are checked by length in min/max rules.
+func isValidationLengthKind(kind reflect.Kind) bool {
+	return kind == reflect.String || kind == reflect.Slice || kind == reflect.Map || kind == reflect.Array
+}